@@ -0,0 +1,76 @@
+// ABOUTME: Optional bearer-token and mutual-TLS protection for the HTTP API
+// ABOUTME: Both stay off unless configured, so a localhost setup needs nothing
+
+import { createServer } from 'node:https';
+import { readFileSync } from 'node:fs';
+import { timingSafeEqual } from 'node:crypto';
+import type { MiddlewareHandler } from 'hono';
+
+// Tokens come from RITUAL_API_TOKENS (comma-separated, one per client so
+// they can be revoked separately) or a single RITUAL_API_TOKEN
+function configuredTokens(): string[] {
+  const raw = process.env.RITUAL_API_TOKENS ?? process.env.RITUAL_API_TOKEN ?? '';
+  return raw
+    .split(',')
+    .map((token) => token.trim())
+    .filter((token) => token.length > 0);
+}
+
+function tokenMatches(given: string, expected: string): boolean {
+  const a = Buffer.from(given);
+  const b = Buffer.from(expected);
+  return a.length === b.length && timingSafeEqual(a, b);
+}
+
+// apiAuth rejects requests without a valid "Authorization: Bearer <token>"
+// header when tokens are configured
+export function apiAuth(): MiddlewareHandler {
+  const tokens = configuredTokens();
+  if (tokens.length === 0) {
+    console.warn('No RITUAL_API_TOKENS set: the API accepts unauthenticated requests');
+  }
+
+  return async (c, next) => {
+    if (tokens.length === 0) {
+      return next();
+    }
+
+    const header = c.req.header('Authorization') ?? '';
+    const match = /^Bearer\s+(.+)$/i.exec(header);
+    if (!match) {
+      c.header('WWW-Authenticate', 'Bearer realm="ritual"');
+      return c.json({ error: 'Missing bearer token', code: 'unauthorized' }, 401);
+    }
+
+    const given = match[1].trim();
+    if (!tokens.some((token) => tokenMatches(given, token))) {
+      c.header('WWW-Authenticate', 'Bearer realm="ritual", error="invalid_token"');
+      return c.json({ error: 'Invalid bearer token', code: 'unauthorized' }, 401);
+    }
+
+    return next();
+  };
+}
+
+// tlsServeOptions returns @hono/node-server options for serving HTTPS when
+// RITUAL_TLS_CERT and RITUAL_TLS_KEY are set. With RITUAL_TLS_CLIENT_CA,
+// clients must also present a certificate signed by that CA (mutual TLS).
+export function tlsServeOptions() {
+  const cert = process.env.RITUAL_TLS_CERT;
+  const key = process.env.RITUAL_TLS_KEY;
+  if (!cert || !key) {
+    return {};
+  }
+
+  const clientCA = process.env.RITUAL_TLS_CLIENT_CA;
+  return {
+    createServer,
+    serverOptions: {
+      cert: readFileSync(cert),
+      key: readFileSync(key),
+      ...(clientCA
+        ? { ca: readFileSync(clientCA), requestCert: true, rejectUnauthorized: true }
+        : {}),
+    },
+  };
+}
@@ -4,7 +4,8 @@
 import { createClient, type Client } from '@libsql/client';
 import { z } from 'zod';
 
-// Database schema types
+// Database schema types. The API's shapes are also described in
+// ../openapi.yaml, which the Go SDK is generated from; keep the two in step.
 export const TaskSchema = z.object({
   id: z.string(),
   name: z.string(),
@@ -13,6 +14,8 @@ export const TaskSchema = z.object({
   schedule: z.string(),
   output: z.string(),
   model: z.string(),
+  variables: z.record(z.string()).default({}),
+  managedBy: z.string().nullable().default(null),
   nextRun: z.string().nullable(),
   lastRun: z.string().nullable(),
   createdAt: z.string(),
@@ -72,6 +75,10 @@ async function runMigrations() {
     )
   `);
 
+  // Columns added after the initial schema
+  await addColumnIfMissing('tasks', 'variables', `TEXT NOT NULL DEFAULT '{}'`);
+  await addColumnIfMissing('tasks', 'managed_by', 'TEXT');
+
   // Create execution_logs table
   await db.execute(`
     CREATE TABLE IF NOT EXISTS execution_logs (
@@ -102,28 +109,34 @@ async function runMigrations() {
   `);
 }
 
+async function addColumnIfMissing(table: string, column: string, definition: string) {
+  const columns = await db.execute(`PRAGMA table_info(${table})`);
+  if (!columns.rows.some(row => row.name === column)) {
+    await db.execute(`ALTER TABLE ${table} ADD COLUMN ${column} ${definition}`);
+  }
+}
+
 // Task operations
 export async function getAllTasks(): Promise<Task[]> {
   const result = await db.execute(`
-    SELECT id, name, status, prompt, schedule, output, model,
+    SELECT id, name, status, prompt, schedule, output, model, variables,
            next_run as nextRun, last_run as lastRun,
-           created_at as createdAt, updated_at as updatedAt, job_id as jobId
+           created_at as createdAt, updated_at as updatedAt, job_id as jobId,
+           managed_by as managedBy
     FROM tasks
     ORDER BY created_at DESC
   `);
   
-  return result.rows.map(row => TaskSchema.parse({
-    ...row,
-    jobId: row.jobId || undefined,
-  }));
+  return result.rows.map(parseTaskRow);
 }
 
 export async function getTaskById(id: string): Promise<Task | null> {
   const result = await db.execute({
     sql: `
-      SELECT id, name, status, prompt, schedule, output, model,
+      SELECT id, name, status, prompt, schedule, output, model, variables,
              next_run as nextRun, last_run as lastRun,
-             created_at as createdAt, updated_at as updatedAt, job_id as jobId
+             created_at as createdAt, updated_at as updatedAt, job_id as jobId,
+           managed_by as managedBy
       FROM tasks
       WHERE id = ?
     `,
@@ -134,21 +147,29 @@ export async function getTaskById(id: string): Promise<Task | null> {
     return null;
   }
   
+  return parseTaskRow(result.rows[0]);
+}
+
+function parseTaskRow(row: Record<string, unknown>): Task {
   return TaskSchema.parse({
-    ...result.rows[0],
-    jobId: result.rows[0].jobId || undefined,
+    ...row,
+    variables: typeof row.variables === 'string' ? JSON.parse(row.variables) : {},
+    jobId: row.jobId || undefined,
   });
 }
 
-export async function createTask(task: Omit<Task, 'id' | 'createdAt' | 'updatedAt'>): Promise<Task> {
+export async function createTask(
+  task: Omit<Task, 'id' | 'createdAt' | 'updatedAt' | 'variables' | 'managedBy'> &
+    { variables?: Record<string, string>; managedBy?: string | null }
+): Promise<Task> {
   const id = crypto.randomUUID();
   const now = new Date().toISOString();
   
   await db.execute({
     sql: `
-      INSERT INTO tasks (id, name, status, prompt, schedule, output, model,
-                        next_run, last_run, created_at, updated_at, job_id)
-      VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+      INSERT INTO tasks (id, name, status, prompt, schedule, output, model, variables,
+                        next_run, last_run, created_at, updated_at, job_id, managed_by)
+      VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
     `,
     args: [
       id,
@@ -158,11 +179,13 @@ export async function createTask(task: Omit<Task, 'id' | 'createdAt' | 'updatedA
       task.schedule,
       task.output,
       task.model,
+      JSON.stringify(task.variables ?? {}),
       task.nextRun,
       task.lastRun,
       now,
       now,
       task.jobId || null,
+      task.managedBy || null,
     ],
   });
   
@@ -174,41 +197,101 @@ export async function createTask(task: Omit<Task, 'id' | 'createdAt' | 'updatedA
   return created;
 }
 
-export async function updateTask(id: string, updates: Partial<Omit<Task, 'id' | 'createdAt'>>): Promise<Task | null> {
+// Fields users edit. Changing one makes a new version of the task, with a
+// new updatedAt and so a new ETag; the scheduler's bookkeeping (nextRun,
+// lastRun, jobId) doesn't, so a task that merely ran isn't a conflict.
+const editableFields = ['name', 'status', 'prompt', 'schedule', 'output', 'model', 'variables', 'managedBy'] as const;
+
+function edits(existing: Task, updates: Partial<Task>): boolean {
+  return editableFields.some(field =>
+    updates[field] !== undefined && JSON.stringify(updates[field]) !== JSON.stringify(existing[field]));
+}
+
+// Columns of the fields updateTask can set
+const taskColumns = {
+  name: 'name',
+  status: 'status',
+  prompt: 'prompt',
+  schedule: 'schedule',
+  output: 'output',
+  model: 'model',
+  variables: 'variables',
+  nextRun: 'next_run',
+  lastRun: 'last_run',
+  jobId: 'job_id',
+  managedBy: 'managed_by',
+} as const;
+
+// TaskConflictError is thrown by a conditional update when the task is no
+// longer at the version it was made against
+export class TaskConflictError extends Error {
+  constructor(id: string) {
+    super(`Task ${id} was changed since it was read`);
+  }
+}
+
+// updateTask writes the given fields only, so concurrent updates of other
+// fields, like the worker recording lastRun, aren't undone. With
+// ifUpdatedAt the write only happens if the task is still at that version,
+// checked by the UPDATE itself so two racing updates can't both succeed.
+export async function updateTask(
+  id: string,
+  updates: Partial<Omit<Task, 'id' | 'createdAt'>>,
+  ifUpdatedAt?: string
+): Promise<Task | null> {
   const existing = await getTaskById(id);
   if (!existing) {
     return null;
   }
-  
-  const updated = {
-    ...existing,
-    ...updates,
-    updatedAt: new Date().toISOString(),
-  };
-  
-  await db.execute({
-    sql: `
-      UPDATE tasks
-      SET name = ?, status = ?, prompt = ?, schedule = ?, output = ?, model = ?,
-          next_run = ?, last_run = ?, updated_at = ?, job_id = ?
-      WHERE id = ?
-    `,
-    args: [
-      updated.name,
-      updated.status,
-      updated.prompt,
-      updated.schedule,
-      updated.output,
-      updated.model,
-      updated.nextRun,
-      updated.lastRun,
-      updated.updatedAt,
-      updated.jobId || null,
-      id,
-    ],
-  });
-  
-  return updated;
+
+  const sets: string[] = [];
+  const args: (string | null)[] = [];
+  for (const [field, column] of Object.entries(taskColumns) as [keyof typeof taskColumns, string][]) {
+    const value = updates[field];
+    if (value === undefined) {
+      continue;
+    }
+    sets.push(`${column} = ?`);
+    if (field === 'variables') {
+      args.push(JSON.stringify(value ?? {}));
+    } else if (field === 'jobId' || field === 'managedBy') {
+      args.push((value as string | null) || null);
+    } else {
+      args.push(value as string | null);
+    }
+  }
+
+  if (edits(existing, updates)) {
+    // Strictly later than the version it replaces, so two edits within the
+    // same millisecond still get different ETags
+    const updatedAt = Math.max(Date.now(), Date.parse(existing.updatedAt) + 1);
+    sets.push('updated_at = ?');
+    args.push(new Date(updatedAt).toISOString());
+  }
+
+  if (sets.length === 0) {
+    if (ifUpdatedAt !== undefined && existing.updatedAt !== ifUpdatedAt) {
+      throw new TaskConflictError(id);
+    }
+    return existing;
+  }
+
+  let sql = `UPDATE tasks SET ${sets.join(', ')} WHERE id = ?`;
+  args.push(id);
+  if (ifUpdatedAt !== undefined) {
+    sql += ' AND updated_at = ?';
+    args.push(ifUpdatedAt);
+  }
+
+  const result = await db.execute({ sql, args });
+  if (result.rowsAffected === 0) {
+    if (ifUpdatedAt !== undefined && await getTaskById(id)) {
+      throw new TaskConflictError(id);
+    }
+    return null;
+  }
+
+  return getTaskById(id);
 }
 
 export async function deleteTask(id: string): Promise<boolean> {
@@ -249,6 +332,21 @@ export async function getTaskExecutionLogs(taskId: string): Promise<ExecutionLog
   return result.rows.map(row => ExecutionLogSchema.parse(row));
 }
 
+export async function getLastSuccessfulOutput(taskId: string): Promise<string> {
+  const result = await db.execute({
+    sql: `
+      SELECT output
+      FROM execution_logs
+      WHERE task_id = ? AND status = 'SUCCESS'
+      ORDER BY executed_at DESC
+      LIMIT 1
+    `,
+    args: [taskId],
+  });
+  
+  return result.rows.length > 0 ? String(result.rows[0].output) : '';
+}
+
 export async function createExecutionLog(log: Omit<ExecutionLog, 'id'>): Promise<ExecutionLog> {
   const id = crypto.randomUUID();
   
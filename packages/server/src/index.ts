@@ -1,9 +1,10 @@
 // ABOUTME: Main entry point for the Ritual server
 // ABOUTME: Handles HTTP API and task scheduling
 
-import { Hono } from "hono";
+import { Hono, type Context } from "hono";
 import { cors } from "hono/cors";
 import { logger } from "hono/logger";
+import { requestId } from "hono/request-id";
 import { serve } from "@hono/node-server";
 import { taskQueue, closeQueue, isRedisConnected } from "./queue.js";
 import { parseSchedule } from "./schedule-parser.js";
@@ -16,16 +17,33 @@ import {
 	updateTask,
 	deleteTask,
 	getAllExecutionLogs,
+	getTaskExecutionLogs,
+	TaskConflictError,
 	type Task,
 	type ExecutionLog,
 } from "./db.js";
 import { initAIService } from "./ai-service.js";
+import { apiAuth, tlsServeOptions } from "./auth.js";
+import { listenOnSocket, removeSocket } from "./socket.js";
 
 const app = new Hono();
 
 // Middleware
-app.use("/*", cors());
+app.use("/*", cors({ exposeHeaders: ["X-Request-Id", "ETag"] }));
+app.use(requestId());
 app.use(logger());
+app.use("/api/*", apiAuth());
+
+// Errors are JSON with a human-readable message and a stable code that
+// clients can branch on; responses carry X-Request-Id for log correlation
+app.onError((err, c) => {
+	console.error(`Request ${c.get("requestId")} failed:`, err);
+	return c.json({ error: "Internal server error", code: "internal_error" }, 500);
+});
+
+app.notFound((c) => {
+	return c.json({ error: "Not found", code: "not_found" }, 404);
+});
 
 
 // Helper functions for job management
@@ -108,6 +126,21 @@ app.get("/health", (c) => {
 	});
 });
 
+// The entity tag of a task version: its updatedAt in epoch milliseconds, so
+// clients can derive it from the timestamp whatever format they parse it into
+function etag(task: Task) {
+	return `"${Date.parse(task.updatedAt)}"`;
+}
+
+// ifMatch reports whether an If-Match header allows changing task
+function ifMatch(header: string, task: Task) {
+	const current = etag(task);
+	return header
+		.split(",")
+		.map((tag) => tag.trim())
+		.some((tag) => tag === "*" || tag === current);
+}
+
 // Task routes
 app.get("/api/tasks", async (c) => {
 	const tasks = await getAllTasks();
@@ -131,38 +164,84 @@ app.post("/api/tasks", async (c) => {
 	return c.json(newTask, 201);
 });
 
-app.put("/api/tasks/:id", async (c) => {
+app.get("/api/tasks/:id", async (c) => {
+	const task = await getTaskById(c.req.param("id"));
+	if (!task) {
+		return c.json({ error: "Task not found", code: "task_not_found" }, 404);
+	}
+	c.header("ETag", etag(task));
+	return c.json(task);
+});
+
+// changeTask applies body to the task named in the route, rescheduling it
+// as needed. PUT and PATCH share it; PATCH only limits the fields.
+async function changeTask(c: Context, body: Partial<Task>) {
 	const id = c.req.param("id");
-	const body = await c.req.json();
 
 	const oldTask = await getTaskById(id);
 	if (!oldTask) {
-		return c.json({ error: "Task not found" }, 404);
+		return c.json({ error: "Task not found", code: "task_not_found" }, 404);
 	}
 
-	// Handle scheduling changes
-	if (body.status !== undefined || body.schedule !== undefined) {
-		const willBeActive = body.status !== undefined ? body.status === "ACTIVE" : oldTask.status === "ACTIVE";
-		const scheduleChanged = body.schedule !== undefined && body.schedule !== oldTask.schedule;
-		
-		// Unschedule old job if needed
-		if (oldTask.status === "ACTIVE" && (!willBeActive || scheduleChanged)) {
-			await unscheduleTask(oldTask);
+	// A conditional update from a client whose copy is out of date would
+	// silently undo someone else's change. The write repeats the check, in
+	// case the task changes while this request is under way.
+	const condition = c.req.header("If-Match");
+	if (condition && !ifMatch(condition, oldTask)) {
+		c.header("ETag", etag(oldTask));
+		return c.json({ error: "Task was changed since it was read", code: "task_conflict" }, 412);
+	}
+
+	// If-Match: * only asks for the task to exist
+	const version = condition && condition.trim() !== "*" ? oldTask.updatedAt : undefined;
+	let updatedTask: Task | null;
+	try {
+		updatedTask = await updateTask(id, body, version);
+	} catch (error) {
+		if (!(error instanceof TaskConflictError)) {
+			throw error;
 		}
-		
-		// Schedule new job if needed
-		if (willBeActive && (oldTask.status !== "ACTIVE" || scheduleChanged)) {
-			const taskToSchedule = { ...oldTask, ...body };
-			await scheduleTask(taskToSchedule);
+		const current = await getTaskById(id);
+		if (current) {
+			c.header("ETag", etag(current));
 		}
+		return c.json({ error: "Task was changed since it was read", code: "task_conflict" }, 412);
 	}
-
-	const updatedTask = await updateTask(id, body);
 	if (!updatedTask) {
-		return c.json({ error: "Failed to update task" }, 500);
+		return c.json({ error: "Task not found", code: "task_not_found" }, 404);
 	}
-	
+
+	// Reschedule only once the change is saved, so a refused update leaves
+	// the job alone
+	const wasActive = oldTask.status === "ACTIVE";
+	const isActive = updatedTask.status === "ACTIVE";
+	const scheduleChanged = updatedTask.schedule !== oldTask.schedule;
+	if (wasActive && (!isActive || scheduleChanged)) {
+		await unscheduleTask(oldTask);
+	}
+	if (isActive && (!wasActive || scheduleChanged)) {
+		await scheduleTask(updatedTask);
+	}
+
+	c.header("ETag", etag(updatedTask));
 	return c.json(updatedTask);
+}
+
+app.put("/api/tasks/:id", async (c) => {
+	return changeTask(c, await c.req.json());
+});
+
+// Fields a PATCH may set; the rest, like id, nextRun and lastRun, belong to
+// the server
+const patchableFields = new Set(["name", "prompt", "schedule", "model", "output", "status", "variables", "managedBy"]);
+
+app.patch("/api/tasks/:id", async (c) => {
+	const body = await c.req.json();
+	const rejected = Object.keys(body).filter((field) => !patchableFields.has(field));
+	if (rejected.length > 0) {
+		return c.json({ error: `Fields can't be patched: ${rejected.join(", ")}`, code: "invalid_patch" }, 400);
+	}
+	return changeTask(c, body);
 });
 
 app.delete("/api/tasks/:id", async (c) => {
@@ -170,7 +249,7 @@ app.delete("/api/tasks/:id", async (c) => {
 
 	const task = await getTaskById(id);
 	if (!task) {
-		return c.json({ error: "Task not found" }, 404);
+		return c.json({ error: "Task not found", code: "task_not_found" }, 404);
 	}
 	
 	// Unschedule the task
@@ -178,20 +257,57 @@ app.delete("/api/tasks/:id", async (c) => {
 	
 	const deleted = await deleteTask(id);
 	if (!deleted) {
-		return c.json({ error: "Failed to delete task" }, 500);
+		return c.json({ error: "Failed to delete task", code: "delete_failed" }, 500);
 	}
 	
 	return c.body(null, 204);
 });
 
+app.post("/api/tasks/:id/run", async (c) => {
+	const id = c.req.param("id");
+
+	const task = await getTaskById(id);
+	if (!task) {
+		return c.json({ error: "Task not found", code: "task_not_found" }, 404);
+	}
+
+	if (!isRedisConnected()) {
+		return c.json({ error: "Queue unavailable: Redis not connected", code: "queue_unavailable" }, 503);
+	}
+
+	// One-off job alongside the repeatable schedule; paused tasks can be run too
+	const job = await taskQueue.add(`task-${task.id}-manual`, {
+		taskId: task.id,
+		prompt: task.prompt,
+		outputChannels: [task.output],
+		model: task.model,
+	});
+
+	return c.json({ jobId: job.id }, 202);
+});
+
 // Log routes
 app.get("/api/logs", async (c) => {
 	const logs = await getAllExecutionLogs();
 	return c.json(logs);
 });
 
+app.get("/api/tasks/:id/logs", async (c) => {
+	const id = c.req.param("id");
+
+	const task = await getTaskById(id);
+	if (!task) {
+		return c.json({ error: "Task not found", code: "task_not_found" }, 404);
+	}
+
+	const logs = await getTaskExecutionLogs(id);
+	return c.json(logs);
+});
+
 // Start server
 const port = process.env.PORT || 8080;
+// A Unix socket path replaces the TCP port, e.g. $XDG_RUNTIME_DIR/ritual/ritual.sock
+const socket = process.env.RITUAL_SOCKET;
 
 async function startServer() {
 	try {
@@ -204,11 +320,20 @@ async function startServer() {
 		// Initialize scheduled tasks
 		await initializeScheduledTasks();
 		
-		console.log(`Server running on port ${port}`);
+		const tls = tlsServeOptions();
+		const https = "createServer" in tls ? " (HTTPS)" : "";
 		
+		if (socket) {
+			listenOnSocket(socket, { fetch: app.fetch, ...tls });
+			console.log(`Server running on ${socket}${https}`);
+			return;
+		}
+		
+		console.log(`Server running on port ${port}${https}`);
 		serve({
 			fetch: app.fetch,
 			port: Number(port),
+			...tls,
 		});
 	} catch (error) {
 		console.error("Failed to start server:", error);
@@ -221,6 +346,9 @@ process.on("SIGTERM", async () => {
 	console.log("SIGTERM received, shutting down gracefully...");
 	await closeQueue();
 	await closeDatabase();
+	if (socket) {
+		removeSocket(socket);
+	}
 	process.exit(0);
 });
 
@@ -228,6 +356,9 @@ process.on("SIGINT", async () => {
 	console.log("SIGINT received, shutting down gracefully...");
 	await closeQueue();
 	await closeDatabase();
+	if (socket) {
+		removeSocket(socket);
+	}
 	process.exit(0);
 });
 
@@ -0,0 +1,98 @@
+// ABOUTME: Expands prompt template directives before a task's prompt is sent to the model
+// ABOUTME: Supports {{date "layout"}}, {{env "NAME"}}, {{var "name"}} and {{last_output}}
+
+export interface PromptContext {
+  runAt: Date;
+  variables: Record<string, string>;
+  lastOutput: string;
+  env?: Record<string, string | undefined>;
+}
+
+// Matches {{name}} and {{name "argument"}}; kept in sync with the TUI's prompt package
+const DIRECTIVE_PATTERN = /\{\{\s*([a-z_]+)(?:\s+"([^"]*)")?\s*\}\}/g;
+
+const DEFAULT_DATE_LAYOUT = '2006-01-02';
+
+// {{env "TEAM"}} reads RITUAL_VAR_TEAM. Only variables with the prefix can
+// be read, so a prompt can't leak secrets like RITUAL_API_TOKENS or the
+// database URL into its run logs.
+export const ENV_PREFIX = 'RITUAL_VAR_';
+
+export function renderPrompt(template: string, context: PromptContext): string {
+  const env = context.env ?? process.env;
+
+  return template.replace(DIRECTIVE_PATTERN, (match, name: string, arg: string | undefined) => {
+    switch (name) {
+      case 'date':
+        return formatGoLayout(context.runAt, arg || DEFAULT_DATE_LAYOUT);
+      case 'env':
+        if (!arg) {
+          throw new Error('{{env}} needs a variable name, e.g. {{env "TEAM"}}');
+        }
+        return env[ENV_PREFIX + arg] ?? '';
+      case 'var':
+        if (!arg || !(arg in context.variables)) {
+          throw new Error(`Variable "${arg ?? ''}" has no value`);
+        }
+        return context.variables[arg];
+      case 'last_output':
+        return context.lastOutput;
+      default:
+        throw new Error(`Unknown template function "${name}"`);
+    }
+  });
+}
+
+const MONTHS = ['January', 'February', 'March', 'April', 'May', 'June',
+  'July', 'August', 'September', 'October', 'November', 'December'];
+const DAYS = ['Sunday', 'Monday', 'Tuesday', 'Wednesday', 'Thursday', 'Friday', 'Saturday'];
+
+const pad = (n: number, width = 2) => String(n).padStart(width, '0');
+
+// Layout tokens from Go's reference time "Mon Jan 2 15:04:05 MST 2006", longest first
+// so that e.g. "January" wins over "Jan" and "2006" over "2"
+const LAYOUT_TOKENS: [string, (d: Date) => string][] = [
+  ['January', d => MONTHS[d.getMonth()]],
+  ['Monday', d => DAYS[d.getDay()]],
+  ['2006', d => String(d.getFullYear())],
+  ['Jan', d => MONTHS[d.getMonth()].slice(0, 3)],
+  ['Mon', d => DAYS[d.getDay()].slice(0, 3)],
+  ['MST', d => new Intl.DateTimeFormat('en-US', { timeZoneName: 'short' })
+    .formatToParts(d).find(p => p.type === 'timeZoneName')?.value ?? ''],
+  ['01', d => pad(d.getMonth() + 1)],
+  ['02', d => pad(d.getDate())],
+  ['06', d => pad(d.getFullYear() % 100)],
+  ['15', d => pad(d.getHours())],
+  ['03', d => pad(d.getHours() % 12 || 12)],
+  ['04', d => pad(d.getMinutes())],
+  ['05', d => pad(d.getSeconds())],
+  ['PM', d => (d.getHours() < 12 ? 'AM' : 'PM')],
+  ['pm', d => (d.getHours() < 12 ? 'am' : 'pm')],
+  ['_2', d => String(d.getDate()).padStart(2, ' ')],
+  ['1', d => String(d.getMonth() + 1)],
+  ['2', d => String(d.getDate())],
+  ['3', d => String(d.getHours() % 12 || 12)],
+  ['4', d => String(d.getMinutes())],
+  ['5', d => String(d.getSeconds())],
+];
+
+// formatGoLayout formats a date with a Go time layout so that the server and
+// the TUI preview render {{date "..."}} identically
+export function formatGoLayout(date: Date, layout: string): string {
+  let out = '';
+  let i = 0;
+
+  outer: while (i < layout.length) {
+    for (const [token, format] of LAYOUT_TOKENS) {
+      if (layout.startsWith(token, i)) {
+        out += format(date);
+        i += token.length;
+        continue outer;
+      }
+    }
+    out += layout[i];
+    i++;
+  }
+
+  return out;
+}
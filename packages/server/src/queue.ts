@@ -4,7 +4,8 @@
 import { Queue, Worker } from 'bullmq';
 import Redis from 'ioredis';
 import { getAIService } from './ai-service.js';
-import { getTaskById, updateTask, createExecutionLog } from './db.js';
+import { getTaskById, updateTask, createExecutionLog, getLastSuccessfulOutput } from './db.js';
+import { renderPrompt } from './prompt-template.js';
 
 // Redis connection with retry logic
 const connection = new Redis({
@@ -59,8 +60,9 @@ export const taskQueue = new Queue('ritual-tasks', {
 export const taskWorker = new Worker(
   'ritual-tasks',
   async (job) => {
-    const { taskId, prompt, outputChannels, model } = job.data;
+    const { taskId, outputChannels, model } = job.data;
     const startTime = Date.now();
+    let prompt: string = job.data.prompt;
     
     console.log(`[${new Date().toISOString()}] Executing task ${taskId}:`);
     console.log(`  Prompt: ${prompt}`);
@@ -74,6 +76,15 @@ export const taskWorker = new Worker(
         throw new Error(`Task ${taskId} not found`);
       }
       
+      // Expand template directives for this run. A repeatable job is added
+      // one occurrence ahead with a delay, so the time it was due is its
+      // timestamp plus that delay, not the timestamp alone.
+      prompt = renderPrompt(task.prompt, {
+        runAt: new Date(job.timestamp + (job.opts.delay ?? 0)),
+        variables: task.variables,
+        lastOutput: await getLastSuccessfulOutput(taskId),
+      });
+      
       // Execute the AI prompt
       const aiService = getAIService();
       const result = await aiService.executePrompt(prompt, model);
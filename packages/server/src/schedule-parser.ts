@@ -8,9 +8,28 @@ export interface ParsedSchedule {
   description: string;
 }
 
+const cronAliases: Record<string, string> = {
+  '@yearly': '0 0 1 1 *',
+  '@annually': '0 0 1 1 *',
+  '@monthly': '0 0 1 * *',
+  '@weekly': '0 0 * * 0',
+  '@daily': '0 0 * * *',
+  '@midnight': '0 0 * * *',
+  '@hourly': '0 * * * *',
+};
+
 export function parseSchedule(schedule: string): ParsedSchedule {
   const normalized = schedule.toLowerCase().trim();
   
+  // Cron aliases
+  const alias = cronAliases[normalized];
+  if (alias) {
+    return {
+      cron: alias,
+      description: normalized,
+    };
+  }
+  
   // Daily patterns
   const dailyMatch = normalized.match(/daily at (\d{1,2}):(\d{2})\s*(am|pm)?/);
   if (dailyMatch) {
@@ -100,6 +119,14 @@ export function parseSchedule(schedule: string): ParsedSchedule {
     };
   }
   
+  // Plain five-field cron expressions
+  if (normalized.split(/\s+/).length === 5 && validateCron(normalized)) {
+    return {
+      cron: normalized,
+      description: normalized,
+    };
+  }
+  
   // If no pattern matches, throw an error
   throw new Error(`Unable to parse schedule: "${schedule}"`);
 }
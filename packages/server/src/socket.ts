@@ -0,0 +1,39 @@
+// ABOUTME: Serves the API on a Unix domain socket instead of a TCP port
+// ABOUTME: Used when the TUI launches its own server, so only the user can reach it
+
+import { chmodSync, existsSync, mkdirSync, unlinkSync } from "node:fs";
+import { dirname } from "node:path";
+import { createAdaptorServer } from "@hono/node-server";
+
+type ServeOptions = Omit<Parameters<typeof createAdaptorServer>[0], "port" | "hostname">;
+
+// listenOnSocket serves options.fetch on the socket at path, readable and
+// writable only by the current user. A socket left behind by a server that
+// didn't shut down cleanly is replaced.
+export function listenOnSocket(path: string, options: ServeOptions) {
+  mkdirSync(dirname(path), { recursive: true, mode: 0o700 });
+  if (existsSync(path)) {
+    unlinkSync(path);
+  }
+
+  const server = createAdaptorServer(options);
+
+  // Create the socket as 0600 rather than tightening it after the fact,
+  // which would leave a window where other users could connect
+  const umask = process.umask(0o177);
+  server.listen(path, () => {
+    process.umask(umask);
+    chmodSync(path, 0o600);
+  });
+
+  return server;
+}
+
+// removeSocket deletes the socket file on shutdown
+export function removeSocket(path: string) {
+  try {
+    unlinkSync(path);
+  } catch {
+    // Already gone
+  }
+}
@@ -0,0 +1,83 @@
+// ABOUTME: Entry point for the ritual binary
+// ABOUTME: Runs a CLI subcommand when one is given, otherwise launches the TUI
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/cli"
+	"github.com/jem-computer/ritual/tui/internal/config"
+	"github.com/jem-computer/ritual/tui/internal/tui"
+	"github.com/spf13/pflag"
+)
+
+// version is set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
+func main() {
+	flags := pflag.NewFlagSet("ritual", pflag.ContinueOnError)
+	flags.SetInterspersed(false) // flags after the subcommand belong to it
+	flags.Usage = func() { cli.Usage(os.Stderr, flags) }
+
+	profileName := flags.String("profile", os.Getenv("RITUAL_PROFILE"), "Server profile from config.yaml (env RITUAL_PROFILE)")
+	server := flags.String("server", os.Getenv("RITUAL_SERVER"), "Ritual server URL, overriding the profile's (env RITUAL_SERVER)")
+	retries := flags.Int("retries", api.DefaultRetryPolicy.MaxAttempts-1, "Retries for failed reads and updates while the server is unreachable (0 disables)")
+	showVersion := flags.BoolP("version", "v", false, "Print the version and exit")
+
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		if err == pflag.ErrHelp {
+			os.Exit(cli.ExitOK)
+		}
+		os.Exit(cli.ExitUsage)
+	}
+
+	if *showVersion {
+		fmt.Println("ritual", version)
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ritual:", err)
+		os.Exit(cli.ExitError)
+	}
+	name, profile, err := cfg.Profile(*profileName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ritual:", err)
+		os.Exit(cli.ExitUsage)
+	}
+	if *server != "" {
+		profile.Server = *server
+	}
+
+	policy := api.DefaultRetryPolicy
+	policy.MaxAttempts = max(*retries, 0) + 1
+	connect := func(p config.Profile) (*api.Client, error) {
+		client, err := p.Client()
+		if err != nil {
+			return nil, err
+		}
+		return client.WithRetry(policy), nil
+	}
+
+	client, err := connect(profile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ritual:", err)
+		os.Exit(cli.ExitError)
+	}
+
+	if args := flags.Args(); len(args) > 0 {
+		os.Exit(cli.Run(client, flags, args))
+	}
+
+	session := tui.Session{Name: name, Profile: profile, Client: client}
+	program := tea.NewProgram(tui.New(cfg, session, connect, version), tea.WithAltScreen())
+	if _, err := program.Run(); err != nil {
+		fmt.Fprintln(os.Stderr, "ritual:", err)
+		os.Exit(cli.ExitError)
+	}
+}
@@ -0,0 +1,43 @@
+// ABOUTME: Generates the Go SDK from the server's OpenAPI spec
+// ABOUTME: Run through go generate in packages/tui/sdk
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jem-computer/ritual/tui/internal/codegen"
+)
+
+func main() {
+	spec := flag.String("spec", "openapi.yaml", "OpenAPI spec to generate from")
+	out := flag.String("out", "", "File to write, or stdout when empty")
+	pkg := flag.String("package", "sdk", "Package name of the generated file")
+	flag.Parse()
+
+	if err := run(*spec, *out, *pkg); err != nil {
+		fmt.Fprintln(os.Stderr, "sdkgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(spec, out, pkg string) error {
+	data, err := os.ReadFile(spec)
+	if err != nil {
+		return err
+	}
+
+	code, err := codegen.Generate(data, codegen.Options{Package: pkg, Source: filepath.Base(spec)})
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		_, err = os.Stdout.Write(code)
+		return err
+	}
+	return os.WriteFile(out, code, 0o644)
+}
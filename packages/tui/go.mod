@@ -8,15 +8,16 @@ require (
 	github.com/charmbracelet/bubbles/v2 v2.0.0-alpha.2
 	github.com/charmbracelet/bubbletea/v2 v2.0.0-alpha.2
 	github.com/charmbracelet/lipgloss/v2 v2.0.0-alpha.2
+	github.com/charmbracelet/x/ansi v0.4.3
+	github.com/charmbracelet/x/term v0.2.1
 	github.com/spf13/pflag v1.0.5
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/atotto/clipboard v0.1.4 // indirect
 	github.com/charmbracelet/colorprofile v0.1.7 // indirect
-	github.com/charmbracelet/x/ansi v0.4.3 // indirect
 	github.com/charmbracelet/x/cellbuf v0.0.3 // indirect
-	github.com/charmbracelet/x/term v0.2.1 // indirect
 	github.com/charmbracelet/x/wcwidth v0.0.0-20241011142426-46044092ad91 // indirect
 	github.com/charmbracelet/x/windows v0.2.1 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
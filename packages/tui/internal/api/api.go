@@ -1,168 +1,265 @@
 // ABOUTME: API client for communicating with the Ritual server
-// ABOUTME: Handles HTTP requests and SSE streaming for real-time updates
+// ABOUTME: Wraps the generated SDK with retries, a circuit breaker, credentials and Unix sockets
 
 package api
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
+	"context"
 	"net/http"
 	"time"
+
+	"github.com/jem-computer/ritual/tui/sdk"
 )
 
+// DefaultTimeout bounds requests whose context has no deadline of its own
+const DefaultTimeout = 30 * time.Second
+
 type Client struct {
 	baseURL    string
+	origin     string // where requests are addressed; differs from baseURL for sockets
 	httpClient *http.Client
+	timeout    time.Duration
+	retry      RetryPolicy
+	conn       *connection
+	auth       *credentials
 }
 
+// NewClient creates a client for the server at baseURL, which is either an
+// http(s) URL or unix:// followed by the path of the server's socket
 func NewClient(baseURL string) *Client {
-	return &Client{
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+	c := &Client{
+		baseURL:    baseURL,
+		origin:     baseURL,
+		httpClient: &http.Client{},
+		timeout:    DefaultTimeout,
+		retry:      DefaultRetryPolicy,
+		conn:       newConnection(),
+		auth:       &credentials{},
 	}
+	if path, ok := socketPath(baseURL); ok {
+		c.origin = socketOrigin
+		c.httpClient = &http.Client{Transport: unixTransport(path)}
+	}
+	return c
 }
 
-// Task represents a scheduled ritual task
-type Task struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Prompt    string    `json:"prompt"`
-	Schedule  string    `json:"schedule"`
-	Model     string    `json:"model"`
-	Output    string    `json:"output"`
-	Status    string    `json:"status"`
-	NextRun   time.Time `json:"nextRun"`
-	LastRun   time.Time `json:"lastRun"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+// WithTimeout returns a copy of the client whose requests give up after d
+// unless their context has an earlier deadline
+func (c *Client) WithTimeout(d time.Duration) *Client {
+	clone := *c
+	clone.timeout = d
+	return &clone
 }
 
-// LogEntry represents an execution log entry
-type LogEntry struct {
-	ID         string    `json:"id"`
-	TaskID     string    `json:"taskId"`
-	TaskName   string    `json:"taskName"`
-	Output     string    `json:"output"`
-	Status     string    `json:"status"`
-	Error      string    `json:"error,omitempty"`
-	ExecutedAt time.Time `json:"executedAt"`
+// BaseURL returns the server URL the client talks to
+func (c *Client) BaseURL() string {
+	return c.baseURL
 }
 
+// Task represents a scheduled ritual task
+type Task = sdk.Task
+
+// LogEntry represents an execution log entry
+type LogEntry = sdk.ExecutionLog
+
 // GetTasks retrieves all tasks
 func (c *Client) GetTasks() ([]Task, error) {
-	resp, err := c.httpClient.Get(c.baseURL + "/api/tasks")
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+	return c.GetTasksContext(context.Background())
+}
 
+// GetTasksContext is GetTasks with a caller-controlled context
+func (c *Client) GetTasksContext(ctx context.Context) ([]Task, error) {
 	var tasks []Task
-	if err := json.NewDecoder(resp.Body).Decode(&tasks); err != nil {
-		return nil, err
-	}
-
-	return tasks, nil
+	err := c.call(ctx, http.MethodGet, func(ctx context.Context, client *sdk.Client) (err error) {
+		tasks, err = client.ListTasks(ctx)
+		return err
+	})
+	return tasks, err
 }
 
-// CreateTask creates a new task
-func (c *Client) CreateTask(task Task) (*Task, error) {
-	body, err := json.Marshal(task)
-	if err != nil {
-		return nil, err
-	}
+// GetTask retrieves a single task
+func (c *Client) GetTask(id string) (*Task, error) {
+	return c.GetTaskContext(context.Background(), id)
+}
 
-	resp, err := c.httpClient.Post(c.baseURL+"/api/tasks", "application/json", bytes.NewReader(body))
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+// GetTaskContext is GetTask with a caller-controlled context
+func (c *Client) GetTaskContext(ctx context.Context, id string) (*Task, error) {
+	var task *Task
+	err := c.call(ctx, http.MethodGet, func(ctx context.Context, client *sdk.Client) (err error) {
+		task, err = client.GetTask(ctx, id)
+		return err
+	})
+	return task, err
+}
 
-	if resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+// CreateTask creates a new task from task's user-editable fields
+func (c *Client) CreateTask(task Task) (*Task, error) {
+	return c.CreateTaskContext(context.Background(), task)
+}
 
-	var created Task
-	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
-		return nil, err
+// CreateTaskContext is CreateTask with a caller-controlled context
+func (c *Client) CreateTaskContext(ctx context.Context, task Task) (*Task, error) {
+	body := sdk.NewTask{
+		Name:      task.Name,
+		Prompt:    task.Prompt,
+		Schedule:  task.Schedule,
+		Model:     task.Model,
+		Output:    task.Output,
+		Status:    task.Status,
+		Variables: task.Variables,
+		ManagedBy: task.ManagedBy,
 	}
 
-	return &created, nil
+	var created *Task
+	err := c.call(ctx, http.MethodPost, func(ctx context.Context, client *sdk.Client) (err error) {
+		created, err = client.CreateTask(ctx, body)
+		return err
+	})
+	return created, err
 }
 
-// UpdateTask updates an existing task
+// UpdateTask updates an existing task. When task.UpdatedAt is set the
+// update is conditional: if the task changed on the server since that
+// version was read, nothing is written and the error satisfies IsConflict.
+// Leave UpdatedAt zero to overwrite whatever is there.
 func (c *Client) UpdateTask(id string, task Task) (*Task, error) {
-	body, err := json.Marshal(task)
-	if err != nil {
-		return nil, err
-	}
+	return c.UpdateTaskContext(context.Background(), id, task)
+}
 
-	req, err := http.NewRequest(http.MethodPut, c.baseURL+"/api/tasks/"+id, bytes.NewReader(body))
-	if err != nil {
-		return nil, err
+// UpdateTaskContext is UpdateTask with a caller-controlled context
+func (c *Client) UpdateTaskContext(ctx context.Context, id string, task Task) (*Task, error) {
+	var params sdk.UpdateTaskParams
+	if !task.UpdatedAt.IsZero() {
+		params.IfMatch = task.ETag()
 	}
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+	var updated *Task
+	err := c.call(ctx, http.MethodPut, func(ctx context.Context, client *sdk.Client) (err error) {
+		updated, err = client.UpdateTask(ctx, id, task, &params)
+		return err
+	})
+	return updated, err
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+// DeleteTask deletes a task
+func (c *Client) DeleteTask(id string) error {
+	return c.DeleteTaskContext(context.Background(), id)
+}
 
-	var updated Task
-	if err := json.NewDecoder(resp.Body).Decode(&updated); err != nil {
-		return nil, err
-	}
+// DeleteTaskContext is DeleteTask with a caller-controlled context
+func (c *Client) DeleteTaskContext(ctx context.Context, id string) error {
+	return c.call(ctx, http.MethodDelete, func(ctx context.Context, client *sdk.Client) error {
+		return client.DeleteTask(ctx, id)
+	})
+}
 
-	return &updated, nil
+// RunTask queues an immediate one-off execution of a task, independent of
+// its schedule and status
+func (c *Client) RunTask(id string) error {
+	return c.RunTaskContext(context.Background(), id)
 }
 
-// DeleteTask deletes a task
-func (c *Client) DeleteTask(id string) error {
-	req, err := http.NewRequest(http.MethodDelete, c.baseURL+"/api/tasks/"+id, nil)
-	if err != nil {
+// RunTaskContext is RunTask with a caller-controlled context
+func (c *Client) RunTaskContext(ctx context.Context, id string) error {
+	return c.call(ctx, http.MethodPost, func(ctx context.Context, client *sdk.Client) error {
+		_, err := client.RunTask(ctx, id)
 		return err
-	}
+	})
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
+// GetLogs retrieves execution logs
+func (c *Client) GetLogs() ([]LogEntry, error) {
+	return c.GetLogsContext(context.Background())
+}
+
+// GetLogsContext is GetLogs with a caller-controlled context
+func (c *Client) GetLogsContext(ctx context.Context) ([]LogEntry, error) {
+	var logs []LogEntry
+	err := c.call(ctx, http.MethodGet, func(ctx context.Context, client *sdk.Client) (err error) {
+		logs, err = client.ListLogs(ctx)
 		return err
-	}
-	defer resp.Body.Close()
+	})
+	return logs, err
+}
 
-	if resp.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+// GetTaskLogs retrieves the most recent execution logs for a single task,
+// newest first
+func (c *Client) GetTaskLogs(id string) ([]LogEntry, error) {
+	return c.GetTaskLogsContext(context.Background(), id)
+}
 
-	return nil
+// GetTaskLogsContext is GetTaskLogs with a caller-controlled context
+func (c *Client) GetTaskLogsContext(ctx context.Context, id string) ([]LogEntry, error) {
+	var logs []LogEntry
+	err := c.call(ctx, http.MethodGet, func(ctx context.Context, client *sdk.Client) (err error) {
+		logs, err = client.ListTaskLogs(ctx, id)
+		return err
+	})
+	return logs, err
 }
 
-// GetLogs retrieves execution logs
-func (c *Client) GetLogs() ([]LogEntry, error) {
-	resp, err := c.httpClient.Get(c.baseURL + "/api/logs")
-	if err != nil {
-		return nil, err
+// call runs op, a single request through the generated client, within the
+// client's timeout. Idempotent methods are retried on transient failures,
+// and every outcome feeds the circuit breaker.
+func (c *Client) call(ctx context.Context, method string, op func(context.Context, *sdk.Client) error) error {
+	if _, ok := ctx.Deadline(); !ok && c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	client := sdk.NewClient(c.origin, doer{c})
+
+	attempts := 1
+	if idempotent(method) {
+		attempts = max(c.retry.MaxAttempts, 1)
 	}
 
-	var logs []LogEntry
-	if err := json.NewDecoder(resp.Body).Decode(&logs); err != nil {
-		return nil, err
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if sleepErr := sleep(ctx, c.retry.backoff(attempt-1)); sleepErr != nil {
+				return err
+			}
+		}
+
+		if !c.conn.allow(time.Now()) {
+			if err != nil {
+				return err // the failure that opened the breaker says more
+			}
+			return ErrCircuitOpen
+		}
+
+		err = op(ctx, client)
+		switch {
+		case err == nil:
+			c.conn.succeeded()
+			return nil
+		case transient(err):
+			c.conn.failed(time.Now())
+		default:
+			// The server answered, or the caller gave up
+			switch {
+			case IsUnauthorized(err):
+				c.conn.rejected()
+			case StatusCode(err) != 0:
+				c.conn.succeeded()
+			default:
+				c.conn.abandoned()
+			}
+			return err
+		}
 	}
+	return err
+}
+
+// doer sends the generated client's requests with the client's
+// credentials and transport
+type doer struct {
+	c *Client
+}
 
-	return logs, nil
+func (d doer) Do(req *http.Request) (*http.Response, error) {
+	d.c.authorize(req)
+	return d.c.httpClient.Do(req)
 }
@@ -0,0 +1,65 @@
+// ABOUTME: Tests for the API client against the in-memory server
+// ABOUTME: Covers partial and conditional updates and how errors are classified
+
+package api_test
+
+import (
+	"testing"
+
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/testutil"
+)
+
+func TestPatchTaskChangesOnlyPatchedFields(t *testing.T) {
+	srv := testutil.NewServer(t)
+	task := srv.AddTask(api.Task{Name: "Digest", Prompt: "Summarise", Status: "ACTIVE"})
+	client := srv.Client()
+
+	// Someone else renames it after we read it
+	if _, err := client.PatchTask(task.ID, api.Patch().Name("Morning digest")); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := client.PatchTask(task.ID, api.Patch().Status("PAUSED"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Name != "Morning digest" || updated.Status != "PAUSED" || updated.Prompt != "Summarise" {
+		t.Errorf("got %+v, want the rename kept and the task paused", updated)
+	}
+}
+
+func TestPatchTaskConflict(t *testing.T) {
+	srv := testutil.NewServer(t)
+	task := srv.AddTask(api.Task{Name: "Digest", Status: "ACTIVE"})
+	client := srv.Client()
+
+	if _, err := client.PatchTask(task.ID, api.Patch().Name("Renamed").IfMatch(task.ETag())); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := client.PatchTask(task.ID, api.Patch().Status("PAUSED").IfMatch(task.ETag()))
+	if !api.IsConflict(err) {
+		t.Fatalf("got %v, want a conflict for a stale ETag", err)
+	}
+	if current, _ := srv.Task(task.ID); current.Status != "ACTIVE" {
+		t.Errorf("status is %s after a refused patch", current.Status)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	srv := testutil.NewServer(t)
+	client := srv.Client()
+
+	if _, err := client.GetTask("missing"); !api.IsNotFound(err) {
+		t.Errorf("GetTask(missing) = %v, want not found", err)
+	}
+
+	srv.RequireToken("secret")
+	if _, err := client.GetTasks(); !api.IsUnauthorized(err) {
+		t.Errorf("GetTasks without a token = %v, want unauthorized", err)
+	}
+	if _, err := srv.Client().GetTasks(); err != nil {
+		t.Errorf("GetTasks with the token: %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+// ABOUTME: Credentials for servers that require authentication
+// ABOUTME: Bearer token shared by a client and its copies, plus TLS for mTLS
+
+package api
+
+import (
+	"crypto/tls"
+	"net/http"
+	"sync"
+)
+
+// credentials is shared by a client and its copies so a token replaced
+// after re-login applies to every component at once
+type credentials struct {
+	mu    sync.RWMutex
+	token string
+}
+
+func (c *credentials) get() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.token
+}
+
+func (c *credentials) set(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+}
+
+// WithToken returns a copy of the client that sends token as a bearer
+// token. The copy no longer shares credentials with the original.
+func (c *Client) WithToken(token string) *Client {
+	clone := *c
+	clone.auth = &credentials{token: token}
+	return &clone
+}
+
+// SetToken replaces the bearer token of this client and every copy sharing
+// its credentials, e.g. after the user logs in again
+func (c *Client) SetToken(token string) {
+	c.auth.set(token)
+}
+
+// HasToken reports whether the client sends a bearer token
+func (c *Client) HasToken() bool {
+	return c.auth.get() != ""
+}
+
+// WithTLS returns a copy of the client that uses cfg for HTTPS, such as a
+// private CA or a client certificate for mutual TLS. Requests over a Unix
+// socket are plain HTTP, so it has no effect on them.
+func (c *Client) WithTLS(cfg *tls.Config) *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if t, ok := c.httpClient.Transport.(*http.Transport); ok {
+		transport = t.Clone()
+	}
+	transport.TLSClientConfig = cfg
+
+	clone := *c
+	clone.httpClient = &http.Client{Transport: transport}
+	return &clone
+}
+
+// authorize adds the bearer token to req, if there is one
+func (c *Client) authorize(req *http.Request) {
+	if token := c.auth.get(); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
@@ -0,0 +1,151 @@
+// ABOUTME: Circuit breaker and observable connection state for the API client
+// ABOUTME: Fails fast while the server is down and probes it again after a cooldown
+
+package api
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the server while the
+// breaker is open after repeated connection failures
+var ErrCircuitOpen = errors.New("server unreachable; waiting before reconnecting")
+
+const (
+	// breakerThreshold is how many consecutive transient failures open the
+	// breaker
+	breakerThreshold = 3
+
+	// breakerCooldown is how long the breaker stays open before letting a
+	// single probe request through
+	breakerCooldown = 5 * time.Second
+)
+
+// ConnState describes the client's view of the server
+type ConnState int
+
+const (
+	Connected    ConnState = iota
+	Reconnecting           // requests are failing and being retried
+	Disconnected           // the breaker is open; requests fail fast
+	Unauthorized           // the server rejected the client's credentials
+)
+
+func (s ConnState) String() string {
+	switch s {
+	case Reconnecting:
+		return "reconnecting"
+	case Disconnected:
+		return "disconnected"
+	case Unauthorized:
+		return "unauthorized"
+	}
+	return "connected"
+}
+
+// connection is shared by a client and its copies
+type connection struct {
+	mu       sync.Mutex
+	state    ConnState
+	failures int       // consecutive transient failures
+	openedAt time.Time // when the breaker last opened
+	probing  bool      // a half-open probe is in flight
+
+	states chan ConnState
+}
+
+func newConnection() *connection {
+	return &connection{states: make(chan ConnState, 1)}
+}
+
+// allow reports whether a request may go to the server. While open it
+// refuses everything until the cooldown passes, then admits one probe.
+func (c *connection) allow(now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state != Disconnected {
+		return true // including Unauthorized: a new token must get through
+	}
+	if c.probing || now.Sub(c.openedAt) < breakerCooldown {
+		return false
+	}
+	c.probing = true
+	return true
+}
+
+// succeeded records a request the server answered
+func (c *connection) succeeded() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.failures = 0
+	c.probing = false
+	c.set(Connected)
+}
+
+// rejected records an answer refusing the client's credentials
+func (c *connection) rejected() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.failures = 0
+	c.probing = false
+	c.set(Unauthorized)
+}
+
+// abandoned records a request that ended without an answer for reasons of
+// its own, such as cancellation, so a probe slot isn't held forever
+func (c *connection) abandoned() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.probing = false
+}
+
+// failed records a transient failure, opening the breaker once they pile up
+func (c *connection) failed(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.failures++
+	if c.probing || c.failures >= breakerThreshold {
+		c.probing = false
+		c.openedAt = now
+		c.set(Disconnected)
+		return
+	}
+	if c.state == Connected {
+		c.set(Reconnecting)
+	}
+}
+
+// set changes the state and publishes it, replacing any value the
+// subscriber hasn't read yet so it always sees the latest. Callers hold mu.
+func (c *connection) set(state ConnState) {
+	if c.state == state {
+		return
+	}
+	c.state = state
+
+	select {
+	case <-c.states:
+	default:
+	}
+	c.states <- state
+}
+
+// ConnState returns the client's current view of the server
+func (c *Client) ConnState() ConnState {
+	c.conn.mu.Lock()
+	defer c.conn.mu.Unlock()
+	return c.conn.state
+}
+
+// ConnStates returns a channel that receives the connection state each time
+// it changes. Only the latest change is buffered, so a slow reader skips
+// intermediate states. The channel is shared: use a single reader.
+func (c *Client) ConnStates() <-chan ConnState {
+	return c.conn.states
+}
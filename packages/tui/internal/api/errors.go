@@ -0,0 +1,48 @@
+// ABOUTME: Classifies failed API responses
+// ABOUTME: Tells not-found, unauthorized, conflicting and unavailable responses apart
+
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jem-computer/ritual/tui/sdk"
+)
+
+// Error is returned when the server answers with an unexpected status
+type Error = sdk.APIError
+
+// StatusCode returns the HTTP status of an *Error in err's chain, or 0 when
+// the request failed before the server answered
+func StatusCode(err error) int {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Status
+	}
+	return 0
+}
+
+// IsNotFound reports whether err means the task or resource doesn't exist
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// IsUnauthorized reports whether the server rejected the client's
+// credentials, or the client sent none
+func IsUnauthorized(err error) bool {
+	status := StatusCode(err)
+	return status == http.StatusUnauthorized || status == http.StatusForbidden
+}
+
+// IsConflict reports whether a conditional update was refused because the
+// task changed on the server since it was read
+func IsConflict(err error) bool {
+	return StatusCode(err) == http.StatusPreconditionFailed
+}
+
+// IsUnavailable reports whether the server said it can't serve the request
+// right now, such as when its queue is down
+func IsUnavailable(err error) bool {
+	return StatusCode(err) == http.StatusServiceUnavailable
+}
@@ -0,0 +1,46 @@
+// ABOUTME: The task fields users see and edit, as opposed to the server's bookkeeping
+// ABOUTME: Used to tell a real edit from a task that merely ran or was rescheduled
+
+package api
+
+import (
+	"sort"
+	"strings"
+)
+
+// TaskField is a task field the user can see and change
+type TaskField struct {
+	Label string
+	Value func(Task) string
+}
+
+// TaskFields lists the user-editable fields. LastRun, NextRun and JobID are
+// left out; the scheduler changes them on every run.
+var TaskFields = []TaskField{
+	{"Name", func(t Task) string { return t.Name }},
+	{"Status", func(t Task) string { return t.Status }},
+	{"Schedule", func(t Task) string { return t.Schedule }},
+	{"Model", func(t Task) string { return t.Model }},
+	{"Output", func(t Task) string { return t.Output }},
+	{"Prompt", func(t Task) string { return t.Prompt }},
+	{"Variables", formatVariables},
+}
+
+func formatVariables(t Task) string {
+	lines := make([]string, 0, len(t.Variables))
+	for name, value := range t.Variables {
+		lines = append(lines, name+"="+value)
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+// Edited reports whether a user-editable field differs between a and b
+func Edited(a, b Task) bool {
+	for _, f := range TaskFields {
+		if f.Value(a) != f.Value(b) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+// ABOUTME: Partial task updates sent with PATCH
+// ABOUTME: A typed builder that records only the fields being changed
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+
+	"github.com/jem-computer/ritual/tui/sdk"
+)
+
+// TaskPatch is a partial update to a task. Build it with Patch and the
+// setters; only the fields that were set are sent, so fields the server
+// manages, like LastRun, and concurrent edits to other fields are never
+// overwritten.
+//
+//	client.PatchTask(id, api.Patch().Status("PAUSED").IfMatch(task.ETag()))
+type TaskPatch struct {
+	body    sdk.TaskPatch
+	ifMatch string
+}
+
+// Patch starts an empty patch
+func Patch() TaskPatch {
+	return TaskPatch{}
+}
+
+func (p TaskPatch) Name(name string) TaskPatch {
+	p.body.Name = &name
+	return p
+}
+
+func (p TaskPatch) Prompt(prompt string) TaskPatch {
+	p.body.Prompt = &prompt
+	return p
+}
+
+func (p TaskPatch) Schedule(schedule string) TaskPatch {
+	p.body.Schedule = &schedule
+	return p
+}
+
+func (p TaskPatch) Model(model string) TaskPatch {
+	p.body.Model = &model
+	return p
+}
+
+func (p TaskPatch) Output(output string) TaskPatch {
+	p.body.Output = &output
+	return p
+}
+
+// Status sets the status, "ACTIVE" or "PAUSED"
+func (p TaskPatch) Status(status string) TaskPatch {
+	p.body.Status = &status
+	return p
+}
+
+// Variables replaces all of the task's prompt variables
+func (p TaskPatch) Variables(variables map[string]string) TaskPatch {
+	variables = maps.Clone(variables)
+	if variables == nil {
+		variables = map[string]string{} // null isn't a valid value
+	}
+	p.body.Variables = &variables
+	return p
+}
+
+// ManagedBy sets the definition file that owns the task; "" releases it
+func (p TaskPatch) ManagedBy(file string) TaskPatch {
+	p.body.ManagedBy = &file
+	return p
+}
+
+// IfMatch makes the patch conditional on the task still being at the
+// version with this ETag; see Task.ETag
+func (p TaskPatch) IfMatch(etag string) TaskPatch {
+	p.ifMatch = etag
+	return p
+}
+
+// IsEmpty reports whether the patch changes nothing
+func (p TaskPatch) IsEmpty() bool {
+	return p.body == sdk.TaskPatch{}
+}
+
+// Apply returns task with the patch's fields set, as the server would
+func (p TaskPatch) Apply(task Task) Task {
+	set(&task.Name, p.body.Name)
+	set(&task.Prompt, p.body.Prompt)
+	set(&task.Schedule, p.body.Schedule)
+	set(&task.Model, p.body.Model)
+	set(&task.Output, p.body.Output)
+	set(&task.Status, p.body.Status)
+	set(&task.ManagedBy, p.body.ManagedBy)
+	if p.body.Variables != nil {
+		task.Variables = maps.Clone(*p.body.Variables)
+	}
+	return task
+}
+
+// Merge returns p with the fields set in later applied on top. The
+// condition of p is kept.
+func (p TaskPatch) Merge(later TaskPatch) TaskPatch {
+	merged := p
+	take(&merged.body.Name, later.body.Name)
+	take(&merged.body.Prompt, later.body.Prompt)
+	take(&merged.body.Schedule, later.body.Schedule)
+	take(&merged.body.Model, later.body.Model)
+	take(&merged.body.Output, later.body.Output)
+	take(&merged.body.Status, later.body.Status)
+	take(&merged.body.ManagedBy, later.body.ManagedBy)
+	take(&merged.body.Variables, later.body.Variables)
+	return merged
+}
+
+// set stores *v in dst when the patch sets it
+func set[T any](dst *T, v *T) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+// take replaces dst with v when the later patch sets it
+func take[T any](dst **T, v *T) {
+	if v != nil {
+		*dst = v
+	}
+}
+
+// MarshalJSON encodes the set fields only. The condition isn't part of
+// the body; it travels in the If-Match header.
+func (p TaskPatch) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.body)
+}
+
+// UnmarshalJSON decodes a patch encoded by MarshalJSON
+func (p *TaskPatch) UnmarshalJSON(data []byte) error {
+	*p = TaskPatch{}
+	return json.Unmarshal(data, &p.body)
+}
+
+// PatchTask changes only the fields set in patch and returns the updated
+// task. A conditional patch fails with an error satisfying IsConflict when
+// the task has changed since.
+func (c *Client) PatchTask(id string, patch TaskPatch) (*Task, error) {
+	return c.PatchTaskContext(context.Background(), id, patch)
+}
+
+// PatchTaskContext is PatchTask with a caller-controlled context
+func (c *Client) PatchTaskContext(ctx context.Context, id string, patch TaskPatch) (*Task, error) {
+	params := sdk.PatchTaskParams{IfMatch: patch.ifMatch}
+
+	var updated *Task
+	err := c.call(ctx, http.MethodPatch, func(ctx context.Context, client *sdk.Client) (err error) {
+		updated, err = client.PatchTask(ctx, id, patch.body, &params)
+		return err
+	})
+	return updated, err
+}
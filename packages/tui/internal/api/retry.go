@@ -0,0 +1,103 @@
+// ABOUTME: Automatic retries for idempotent requests
+// ABOUTME: Exponential backoff with full jitter on connection failures and gateway errors
+
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how failed idempotent requests (GET, PUT, DELETE)
+// are retried. Creating a task or queueing a run is never retried, since a
+// request that timed out may still have succeeded.
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts including the first; 1 disables retries
+	BaseDelay   time.Duration // delay ceiling before the first retry
+	MaxDelay    time.Duration // cap on the delay ceiling as it doubles
+}
+
+// DefaultRetryPolicy rides out a server or Redis restart of a few seconds
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   250 * time.Millisecond,
+	MaxDelay:    4 * time.Second,
+}
+
+// NoRetry makes every request a single attempt
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// WithRetry returns a copy of the client using policy. The copy shares the
+// original's circuit breaker and connection state.
+func (c *Client) WithRetry(policy RetryPolicy) *Client {
+	clone := *c
+	clone.retry = policy
+	return &clone
+}
+
+// backoff returns the delay before retry number n (0 for the first retry):
+// a random duration up to BaseDelay*2^n, capped at MaxDelay. Randomising the
+// whole interval keeps several clients from retrying in lockstep.
+func (p RetryPolicy) backoff(n int) time.Duration {
+	ceiling := p.BaseDelay << n
+	if ceiling <= 0 || ceiling > p.MaxDelay {
+		ceiling = p.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling)
+}
+
+// idempotent reports whether repeating the request is safe
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// transient reports whether err looks like the server being briefly away:
+// a failed connection or a proxy/gateway answering for it. Cancellation by
+// the caller and ordinary API errors are not transient.
+func transient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.Status {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			// The server's own 503s carry a code; those are answers, not outages
+			return apiErr.Code == ""
+		}
+		return false
+	}
+
+	// A certificate the client rejects won't fix itself on retry
+	var certErr *tls.CertificateVerificationError
+	if errors.As(err, &certErr) {
+		return false
+	}
+
+	return !errors.Is(err, ErrCircuitOpen)
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
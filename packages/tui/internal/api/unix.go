@@ -0,0 +1,41 @@
+// ABOUTME: Unix domain socket transport for a server on the same machine
+// ABOUTME: Lets base URLs like unix:///run/user/1000/ritual/ritual.sock reach the server without TCP
+
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// UnixScheme prefixes base URLs that name a socket path rather than a host
+const UnixScheme = "unix://"
+
+// socketOrigin stands in for the host in requests sent over a socket. The
+// transport ignores it, but requests need a well-formed URL.
+const socketOrigin = "http://ritual.sock"
+
+// SocketURL returns the base URL for the socket at path
+func SocketURL(path string) string {
+	return UnixScheme + path
+}
+
+// socketPath returns the socket path of a unix:// base URL
+func socketPath(baseURL string) (string, bool) {
+	return strings.CutPrefix(baseURL, UnixScheme)
+}
+
+// unixTransport returns a transport whose connections all go to the socket
+// at path, whatever host the request names
+func unixTransport(path string) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+
+	var dialer net.Dialer
+	transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "unix", path)
+	}
+	return transport
+}
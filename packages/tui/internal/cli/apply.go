@@ -0,0 +1,105 @@
+// ABOUTME: ritual apply command for rituals-as-code
+// ABOUTME: Prints a plan diffing definition files against the server, then applies it
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jem-computer/ritual/tui/internal/ritualfile"
+)
+
+var applyCommand = command{
+	name:    "apply",
+	args:    "-f <dir|file> [--dry-run] [--prune]",
+	summary: "Sync tasks to a directory of YAML ritual definitions",
+}
+
+func init() {
+	applyCommand.run = runApply
+	register(applyCommand)
+}
+
+// planSymbols prefix each line of the printed plan
+var planSymbols = map[ritualfile.Action]string{
+	ritualfile.ActionCreate:    "+",
+	ritualfile.ActionUpdate:    "~",
+	ritualfile.ActionDelete:    "-",
+	ritualfile.ActionUnchanged: " ",
+	ritualfile.ActionOrphaned:  "?",
+}
+
+func runApply(e *env, args []string) error {
+	flags := newFlagSet(e, applyCommand)
+	path := flags.StringP("filename", "f", "", "Directory or file of ritual definitions")
+	dryRun := flags.Bool("dry-run", false, "Print the plan without changing anything")
+	prune := flags.Bool("prune", false, "Delete tasks managed from under -f whose definition no longer exists")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *path == "" {
+		return usagef("-f is required")
+	}
+	if flags.NArg() > 0 {
+		return usagef("unexpected argument %q", flags.Arg(0))
+	}
+
+	// Sources are recorded relative to the working directory, which is
+	// normally the repository root in CI
+	base, err := os.Getwd()
+	if err != nil {
+		base = filepath.Dir(*path)
+	}
+
+	defs, err := ritualfile.LoadDefinitions(*path, base)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := e.client.GetTasks()
+	if err != nil {
+		return err
+	}
+
+	plan := ritualfile.PlanApply(defs, tasks, ritualfile.SourcePath(*path, base), *prune)
+	printPlan(e, plan)
+
+	if *dryRun || !plan.HasChanges() {
+		return nil
+	}
+
+	fmt.Fprintln(e.stdout)
+	return plan.Apply(e.client, func(c ritualfile.Change) {
+		fmt.Fprintf(e.stdout, "%s %s: done\n", c.Action, c.Name)
+	})
+}
+
+func printPlan(e *env, plan ritualfile.Plan) {
+	width := 0
+	for _, c := range plan {
+		width = max(width, len(c.Name))
+	}
+
+	for _, c := range plan {
+		detail := c.Source
+		switch c.Action {
+		case ritualfile.ActionUpdate:
+			detail = strings.Join(c.Fields, ", ")
+		case ritualfile.ActionOrphaned:
+			detail = c.Source + " is gone (use --prune to delete)"
+		}
+		fmt.Fprintf(e.stdout, "%s %-9s  %-*s  %s\n", planSymbols[c.Action], c.Action, width, c.Name, detail)
+	}
+
+	counts := plan.Counts()
+	fmt.Fprintf(e.stdout, "\nPlan: %d to create, %d to update, %d to delete, %d unchanged",
+		counts[ritualfile.ActionCreate], counts[ritualfile.ActionUpdate],
+		counts[ritualfile.ActionDelete], counts[ritualfile.ActionUnchanged])
+	if n := counts[ritualfile.ActionOrphaned]; n > 0 {
+		fmt.Fprintf(e.stdout, ", %d orphaned", n)
+	}
+	fmt.Fprintln(e.stdout, ".")
+}
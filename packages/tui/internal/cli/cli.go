@@ -0,0 +1,152 @@
+// ABOUTME: Non-interactive subcommands of the ritual binary
+// ABOUTME: Dispatches ritual <command> [flags] and maps failures to exit codes
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/spf13/pflag"
+)
+
+// Exit codes returned by Run
+const (
+	ExitOK       = 0
+	ExitError    = 1 // the command failed
+	ExitUsage    = 2 // bad flags or arguments
+	ExitNotFound = 3 // a referenced task doesn't exist
+)
+
+// command is a single subcommand
+type command struct {
+	name    string
+	args    string // argument synopsis for usage, e.g. "<file>"
+	summary string
+	run     func(env *env, args []string) error
+	hidden  bool // left out of usage, e.g. helpers called by completion scripts
+}
+
+// env is what a command runs against
+type env struct {
+	client *api.Client
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	global *pflag.FlagSet // the binary's own flags, given before the command
+
+	// flags is the last flag set a command created, which lets completion
+	// discover a command's flags by running it with --help
+	flags *pflag.FlagSet
+}
+
+var commands []command
+
+func register(c command) {
+	commands = append(commands, c)
+	sort.Slice(commands, func(i, j int) bool { return commands[i].name < commands[j].name })
+}
+
+// usageError marks failures caused by how the command was invoked
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string { return e.err.Error() }
+
+func usagef(format string, args ...any) error {
+	return usageError{fmt.Errorf(format, args...)}
+}
+
+// Run executes the subcommand named by args[0] and returns the process
+// exit code. global holds the flags parsed before the command.
+func Run(client *api.Client, global *pflag.FlagSet, args []string) int {
+	e := &env{client: client, stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr, global: global}
+
+	for _, c := range commands {
+		if c.name != args[0] {
+			continue
+		}
+
+		err := c.run(e, args[1:])
+		var usage usageError
+		var notFound notFoundError
+		switch {
+		case err == nil:
+			return ExitOK
+		case errors.Is(err, pflag.ErrHelp):
+			return ExitOK
+		case errors.As(err, &usage):
+			fmt.Fprintf(e.stderr, "ritual %s: %v\n", c.name, err)
+			return ExitUsage
+		case errors.As(err, &notFound), api.IsNotFound(err):
+			fmt.Fprintf(e.stderr, "ritual %s: %v\n", c.name, err)
+			return ExitNotFound
+		case api.IsUnauthorized(err):
+			fmt.Fprintf(e.stderr, "ritual %s: %v\n", c.name, err)
+			fmt.Fprintln(e.stderr, "Set RITUAL_TOKEN, or the profile's token in the ritual config file when using a profile.")
+			return ExitError
+		case api.IsConflict(err):
+			fmt.Fprintf(e.stderr, "ritual %s: %v\n", c.name, err)
+			fmt.Fprintln(e.stderr, "Someone else changed the task at the same time; run the command again to apply yours on top.")
+			return ExitError
+		default:
+			fmt.Fprintf(e.stderr, "ritual %s: %v\n", c.name, err)
+			return ExitError
+		}
+	}
+
+	fmt.Fprintf(e.stderr, "ritual: unknown command %q\n\n", args[0])
+	Usage(e.stderr, global)
+	return ExitUsage
+}
+
+// Usage prints the top-level help, including the global flags when given
+func Usage(w io.Writer, global *pflag.FlagSet) {
+	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintln(w, "  ritual [flags]                 Launch the terminal UI")
+	fmt.Fprintln(w, "  ritual [flags] <command> ...   Run a command without the UI")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands {
+		if c.hidden {
+			continue
+		}
+		fmt.Fprintf(w, "  %s %s\n      %s\n", c.name, c.args, c.summary)
+	}
+	if global != nil {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Flags:")
+		fmt.Fprint(w, global.FlagUsages())
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "ritual <command> --help" for a command's flags.`)
+}
+
+// newFlagSet returns a flag set for a subcommand whose --help prints its
+// synopsis
+func newFlagSet(e *env, c command) *pflag.FlagSet {
+	flags := pflag.NewFlagSet(c.name, pflag.ContinueOnError)
+	flags.SetOutput(e.stderr)
+	e.flags = flags
+	flags.Usage = func() {
+		fmt.Fprintf(e.stderr, "Usage: ritual %s %s\n\n%s\n\n", c.name, c.args, c.summary)
+		fmt.Fprint(e.stderr, flags.FlagUsages())
+	}
+	return flags
+}
+
+// parseFlags parses args, turning flag errors into usage errors
+func parseFlags(flags *pflag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return err
+		}
+		return usageError{err}
+	}
+	return nil
+}
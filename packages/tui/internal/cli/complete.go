@@ -0,0 +1,501 @@
+// ABOUTME: Hidden __complete command that computes shell completion candidates
+// ABOUTME: Completes commands, flags and flag values, with task data cached briefly
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/config"
+	"github.com/jem-computer/ritual/tui/internal/templates"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// completionTimeout bounds the server request so a slow or stopped
+	// server never stalls the shell
+	completionTimeout = 500 * time.Millisecond
+
+	// completionCacheTTL is how long fetched tasks are reused before asking
+	// the server again; stale data is still used when the server is down
+	completionCacheTTL = 30 * time.Second
+
+	// filesDirective tells the completion script to complete file paths
+	filesDirective = ":files"
+)
+
+var completeCommand = command{
+	name:    "__complete",
+	args:    "<word>...",
+	summary: "Print completion candidates for the given words (used by completion scripts)",
+	hidden:  true,
+}
+
+func init() {
+	completeCommand.run = runComplete
+	register(completeCommand)
+}
+
+// candidate is one completion suggestion
+type candidate struct {
+	value       string
+	description string
+}
+
+// runComplete takes the words after "ritual", the last being the partial
+// word under the cursor, and prints the matching candidates
+func runComplete(e *env, args []string) error {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+
+	c := completer{env: e}
+	candidates, files := c.complete(args[:len(args)-1], args[len(args)-1])
+	if files {
+		fmt.Fprintln(e.stdout, filesDirective)
+		return nil
+	}
+
+	current := args[len(args)-1]
+	for _, cand := range candidates {
+		if strings.HasPrefix(cand.value, current) {
+			fmt.Fprintf(e.stdout, "%s\t%s\n", cand.value, cand.description)
+		}
+	}
+	return nil
+}
+
+// completer works out candidates for one completion request
+type completer struct {
+	env     *env
+	server  string // from a --server typed on the command line, if any
+	profile string // likewise for --profile
+	tasks   []cachedTask
+	loaded  bool
+}
+
+func (c *completer) complete(words []string, current string) ([]candidate, bool) {
+	// Global flags come before the command
+	i := 0
+	for i < len(words) && strings.HasPrefix(words[i], "-") {
+		name, value, hasValue := strings.Cut(words[i], "=")
+		flag := lookupFlag(c.env.global, name)
+		if flag != nil && takesValue(flag) && !hasValue && i+1 < len(words) {
+			i++
+			value = words[i]
+		}
+		if flag != nil {
+			switch flag.Name {
+			case "server":
+				c.server = value
+			case "profile":
+				c.profile = value
+			}
+		}
+		i++
+	}
+
+	if i == len(words) {
+		if len(words) > 0 && expectsValue(c.env.global, words[len(words)-1]) {
+			return globalFlagValues(lookupFlag(c.env.global, words[len(words)-1]), ""), false
+		}
+		if name, _, ok := strings.Cut(current, "="); ok && strings.HasPrefix(current, "--") {
+			return globalFlagValues(lookupFlag(c.env.global, name), name+"="), false
+		}
+		if strings.HasPrefix(current, "-") {
+			return flagCandidates(c.env.global), false
+		}
+		return commandCandidates(), false
+	}
+
+	var cmd *command
+	for j := range commands {
+		if commands[j].name == words[i] && !commands[j].hidden {
+			cmd = &commands[j]
+		}
+	}
+	if cmd == nil {
+		return nil, false
+	}
+
+	rest := words[i+1:]
+	describeArgs := []string{"--help"}
+	switch cmd.name {
+	case "completion":
+		if len(rest) == 0 {
+			var shells []candidate
+			for _, shell := range completionShells {
+				shells = append(shells, candidate{shell, ""})
+			}
+			return shells, false
+		}
+		return nil, false
+	case "tasks":
+		if len(rest) == 0 {
+			var subs []candidate
+			for _, sub := range taskSubcommands {
+				subs = append(subs, candidate{sub.name, sub.summary})
+			}
+			return subs, false
+		}
+		describeArgs = []string{rest[0], "--help"}
+		rest = rest[1:]
+	}
+
+	flags := describeFlags(*cmd, describeArgs)
+	if flags == nil {
+		return nil, false
+	}
+
+	// A value for the previous flag, or one given inline as --flag=value
+	if len(rest) > 0 && expectsValue(flags, rest[len(rest)-1]) {
+		return c.flagValues(lookupFlag(flags, rest[len(rest)-1]), current, "")
+	}
+	if name, value, ok := strings.Cut(current, "="); ok && strings.HasPrefix(current, "--") {
+		if flag := lookupFlag(flags, name); flag != nil {
+			return c.flagValues(flag, value, name+"=")
+		}
+		return nil, false
+	}
+
+	if strings.HasPrefix(current, "-") {
+		return flagCandidates(flags), false
+	}
+
+	if positionalCount(flags, rest) > 0 {
+		return nil, false
+	}
+	switch {
+	case cmd.name == "import":
+		return nil, true
+	case cmd.name == "tasks" && words[i+1] != "list" && words[i+1] != "create":
+		return c.taskCandidates(), false
+	}
+	return nil, false
+}
+
+// flagValues suggests values for flag. prefix is prepended to each value
+// when completing the --flag=value form.
+func (c *completer) flagValues(flag *pflag.Flag, current, prefix string) ([]candidate, bool) {
+	var values []candidate
+	switch flag.Name {
+	case "filename", "prompt-file":
+		if prefix != "" {
+			return nil, false
+		}
+		return nil, true
+	case "task":
+		values = c.taskCandidates()
+	case "ids":
+		// Comma-separated: keep the IDs already typed and complete the last
+		done := ""
+		if i := strings.LastIndexByte(current, ','); i >= 0 {
+			done = current[:i+1]
+		}
+		for _, task := range c.loadTasks() {
+			values = append(values, candidate{done + task.ID, task.Name})
+		}
+	case "model":
+		for _, model := range c.distinct(func(t cachedTask) string { return t.Model }) {
+			values = append(values, candidate{model, "model"})
+		}
+	case "output":
+		for _, output := range c.distinct(func(t cachedTask) string { return t.Output }) {
+			values = append(values, candidate{output, "output"})
+		}
+	case "on-duplicate":
+		values = []candidate{
+			{"skip", "keep the existing task"},
+			{"overwrite", "replace the existing task"},
+			{"rename", "import under a new name"},
+		}
+	case "format":
+		values = []candidate{{"yaml", ""}, {"json", ""}}
+	}
+
+	for i := range values {
+		values[i].value = prefix + values[i].value
+	}
+	return values, false
+}
+
+// globalFlagValues suggests values for a flag given before the command
+func globalFlagValues(flag *pflag.Flag, prefix string) []candidate {
+	if flag == nil || flag.Name != "profile" {
+		return nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+	var values []candidate
+	for _, name := range cfg.Names() {
+		values = append(values, candidate{prefix + name, cfg.Profiles[name].Server})
+	}
+	return values
+}
+
+// taskCandidates offers every task by name and by ID
+func (c *completer) taskCandidates() []candidate {
+	tasks := c.loadTasks()
+	candidates := make([]candidate, 0, 2*len(tasks))
+	for _, task := range tasks {
+		candidates = append(candidates, candidate{task.Name, shortID(task.ID) + " " + strings.ToLower(task.Status)})
+	}
+	for _, task := range tasks {
+		candidates = append(candidates, candidate{task.ID, task.Name})
+	}
+	return candidates
+}
+
+// distinct returns the sorted unique values of field across the server's
+// tasks and the built-in templates. The server has no catalogue of models or
+// outputs, so the ones already in use are the best suggestions.
+func (c *completer) distinct(field func(cachedTask) string) []string {
+	seen := make(map[string]bool)
+	for _, task := range c.loadTasks() {
+		seen[field(task)] = true
+	}
+	for _, t := range templates.Builtins() {
+		seen[field(cachedTask{Model: t.Model, Output: t.Output})] = true
+	}
+	delete(seen, "")
+
+	values := make([]string, 0, len(seen))
+	for value := range seen {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// loadTasks returns the server's tasks from the cache when it's fresh,
+// otherwise from the server, falling back to a stale cache when the server
+// can't be reached in time
+func (c *completer) loadTasks() []cachedTask {
+	if c.loaded {
+		return c.tasks
+	}
+	c.loaded = true
+
+	client := c.env.client
+	if c.server != "" || c.profile != "" {
+		client = c.typedClient()
+	}
+	if client == nil {
+		return nil
+	}
+
+	path := completionCachePath(client.BaseURL())
+	cache, cacheErr := readCompletionCache(path)
+	if cacheErr == nil && time.Since(cache.FetchedAt) < completionCacheTTL {
+		c.tasks = cache.Tasks
+		return c.tasks
+	}
+
+	tasks, err := client.WithTimeout(completionTimeout).WithRetry(api.NoRetry).GetTasks()
+	if err != nil {
+		c.tasks = cache.Tasks
+		return c.tasks
+	}
+
+	cache = completionCache{Server: client.BaseURL(), FetchedAt: time.Now()}
+	for _, task := range tasks {
+		cache.Tasks = append(cache.Tasks, cachedTask{
+			ID:     task.ID,
+			Name:   task.Name,
+			Status: task.Status,
+			Model:  task.Model,
+			Output: task.Output,
+		})
+	}
+	sort.Slice(cache.Tasks, func(i, j int) bool { return cache.Tasks[i].Name < cache.Tasks[j].Name })
+	writeCompletionCache(path, cache)
+
+	c.tasks = cache.Tasks
+	return c.tasks
+}
+
+// completionCache is what's kept on disk between completions
+type completionCache struct {
+	Server    string       `json:"server"`
+	FetchedAt time.Time    `json:"fetchedAt"`
+	Tasks     []cachedTask `json:"tasks"`
+}
+
+// cachedTask holds only the fields completion needs
+type cachedTask struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Model  string `json:"model,omitempty"`
+	Output string `json:"output,omitempty"`
+}
+
+// typedClient builds a client for the --profile and --server typed on the
+// command line, which may differ from the ones the completion runs with
+func (c *completer) typedClient() *api.Client {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+	_, profile, err := cfg.Profile(c.profile)
+	if err != nil {
+		return nil
+	}
+	if c.server != "" {
+		profile.Server = c.server
+	}
+
+	client, err := profile.Client()
+	if err != nil {
+		return nil
+	}
+	return client
+}
+
+// completionCachePath returns the cache file for server under
+// $XDG_CACHE_HOME/ritual, one file per server so switching servers never
+// suggests the wrong tasks. Empty when there's no cache directory.
+func completionCachePath(server string) string {
+	dir, err := config.CacheDir()
+	if err != nil {
+		return ""
+	}
+
+	h := fnv.New64a()
+	io.WriteString(h, server)
+	return filepath.Join(dir, fmt.Sprintf("completion-%x.json", h.Sum64()))
+}
+
+func readCompletionCache(path string) (completionCache, error) {
+	var cache completionCache
+	if path == "" {
+		return cache, os.ErrNotExist
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache, err
+	}
+	err = json.Unmarshal(data, &cache)
+	return cache, err
+}
+
+// writeCompletionCache saves cache, ignoring failures: completion still
+// works without it, just more slowly
+func writeCompletionCache(path string, cache completionCache) {
+	if path == "" {
+		return
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+
+	// Write then rename so a concurrent completion never reads half a file
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".completion-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// describeFlags returns the flags of cmd by running it with --help, which
+// every command handles before doing any work
+func describeFlags(cmd command, args []string) *pflag.FlagSet {
+	e := &env{stdin: strings.NewReader(""), stdout: io.Discard, stderr: io.Discard}
+	cmd.run(e, args)
+	return e.flags
+}
+
+func commandCandidates() []candidate {
+	var candidates []candidate
+	for _, c := range commands {
+		if !c.hidden {
+			candidates = append(candidates, candidate{c.name, c.summary})
+		}
+	}
+	return candidates
+}
+
+func flagCandidates(flags *pflag.FlagSet) []candidate {
+	var candidates []candidate
+	if flags == nil {
+		return nil
+	}
+	flags.VisitAll(func(f *pflag.Flag) {
+		if !f.Hidden {
+			candidates = append(candidates, candidate{"--" + f.Name, f.Usage})
+		}
+	})
+	return candidates
+}
+
+// lookupFlag finds the flag named by word, in --long or -s form
+func lookupFlag(flags *pflag.FlagSet, word string) *pflag.Flag {
+	if flags == nil {
+		return nil
+	}
+	if name, ok := strings.CutPrefix(word, "--"); ok {
+		return flags.Lookup(name)
+	}
+	if name, ok := strings.CutPrefix(word, "-"); ok && len(name) == 1 {
+		return flags.ShorthandLookup(name)
+	}
+	return nil
+}
+
+// takesValue reports whether flag consumes the following word
+func takesValue(flag *pflag.Flag) bool {
+	return flag.NoOptDefVal == ""
+}
+
+// expectsValue reports whether word is a flag still waiting for its value
+func expectsValue(flags *pflag.FlagSet, word string) bool {
+	if strings.Contains(word, "=") {
+		return false
+	}
+	flag := lookupFlag(flags, word)
+	return flag != nil && takesValue(flag)
+}
+
+// positionalCount counts the non-flag arguments in words
+func positionalCount(flags *pflag.FlagSet, words []string) int {
+	count := 0
+	for i := 0; i < len(words); i++ {
+		switch {
+		case words[i] == "--":
+			return count + len(words) - i - 1
+		case strings.HasPrefix(words[i], "-"):
+			if expectsValue(flags, words[i]) {
+				i++
+			}
+		default:
+			count++
+		}
+	}
+	return count
+}
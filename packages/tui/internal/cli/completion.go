@@ -0,0 +1,137 @@
+// ABOUTME: ritual completion command printing bash, zsh and fish scripts
+// ABOUTME: The scripts delegate to the hidden __complete command for candidates
+
+package cli
+
+import (
+	"fmt"
+)
+
+var completionCommand = command{
+	name:    "completion",
+	args:    "<bash|zsh|fish>",
+	summary: "Print a shell completion script",
+}
+
+// completionShells lists the supported shells in the order they're offered
+var completionShells = []string{"bash", "zsh", "fish"}
+
+func init() {
+	completionCommand.run = runCompletion
+	register(completionCommand)
+}
+
+func runCompletion(e *env, args []string) error {
+	flags := newFlagSet(e, completionCommand)
+	flags.Usage = func() {
+		fmt.Fprintf(e.stderr, "Usage: ritual completion <bash|zsh|fish>\n\n")
+		fmt.Fprintln(e.stderr, "Load completions in the current shell with:")
+		fmt.Fprintln(e.stderr, "  bash:  source <(ritual completion bash)")
+		fmt.Fprintln(e.stderr, "  zsh:   source <(ritual completion zsh)")
+		fmt.Fprintln(e.stderr, "  fish:  ritual completion fish | source")
+		fmt.Fprintln(e.stderr)
+		fmt.Fprintln(e.stderr, "Add the same line to your shell's startup file to keep them.")
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usagef("expected one shell: bash, zsh or fish")
+	}
+
+	var script string
+	switch flags.Arg(0) {
+	case "bash":
+		script = bashCompletion
+	case "zsh":
+		script = zshCompletion
+	case "fish":
+		script = fishCompletion
+	default:
+		return usagef("unsupported shell %q; use bash, zsh or fish", flags.Arg(0))
+	}
+
+	_, err := fmt.Fprint(e.stdout, script)
+	return err
+}
+
+// Each script passes the words typed so far, including the partial word
+// under the cursor, to "ritual __complete". It prints one candidate per
+// line as "value<TAB>description", or the single line ":files" when a file
+// path is expected.
+
+const bashCompletion = `# bash completion for ritual
+# Load with: source <(ritual completion bash)
+
+_ritual() {
+    local line="${COMP_LINE:0:COMP_POINT}"
+    local -a words
+    read -ra words <<< "$line"
+    [[ $line == *[[:space:]] ]] && words+=("")
+
+    local IFS=$'\n'
+    local out
+    out=$(ritual __complete "${words[@]:1}" 2>/dev/null) || return
+
+    local cur="${words[-1]}"
+    if [[ $out == ":files" ]]; then
+        COMPREPLY=($(compgen -f -- "${COMP_WORDS[COMP_CWORD]}"))
+        compopt -o filenames 2>/dev/null
+        return
+    fi
+
+    # bash splits words at "=" and ":", so strip whatever part of the
+    # current word it has already treated as separate words
+    local done="${cur%"${COMP_WORDS[COMP_CWORD]}"}"
+    local candidate value
+    COMPREPLY=()
+    for candidate in $out; do
+        value="${candidate%%$'\t'*}"
+        COMPREPLY+=("$(printf '%q' "${value#"$done"}")")
+    done
+}
+
+complete -F _ritual ritual
+`
+
+const zshCompletion = `#compdef ritual
+# zsh completion for ritual
+# Load with: source <(ritual completion zsh)
+
+_ritual() {
+  local out line
+  local -a candidates
+  out=$(ritual __complete "${(@)words[2,CURRENT]}" 2>/dev/null) || return
+
+  if [[ $out == ":files" ]]; then
+    _files
+    return
+  fi
+
+  for line in "${(@f)out}"; do
+    [[ -n $line ]] || continue
+    candidates+=("${${line%%$'\t'*}//:/\\:}:${line#*$'\t'}")
+  done
+  _describe 'ritual' candidates
+}
+
+compdef _ritual ritual
+`
+
+const fishCompletion = `# fish completion for ritual
+# Load with: ritual completion fish | source
+
+function __ritual_complete
+    set -l cur (commandline -ct)
+    set -l words (commandline -opc) "$cur"
+    set -l out (ritual __complete $words[2..-1] 2>/dev/null)
+
+    if test "$out" = ":files"
+        __fish_complete_path "$cur"
+        return
+    end
+    printf '%s\n' $out
+end
+
+complete -c ritual -f -a '(__ritual_complete)'
+`
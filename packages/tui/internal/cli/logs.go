@@ -0,0 +1,193 @@
+// ABOUTME: ritual logs command for reading execution history from scripts
+// ABOUTME: Filters by task and time, and can follow new executions as they land
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jem-computer/ritual/tui/internal/api"
+)
+
+// followInterval is how often --follow polls for new executions
+const followInterval = 2 * time.Second
+
+var logsCommand = command{
+	name:    "logs",
+	args:    "[--task <task>] [--since <duration|time>] [--follow] [--json|--yaml]",
+	summary: "Print execution logs",
+}
+
+func init() {
+	logsCommand.run = runLogs
+	register(logsCommand)
+}
+
+func runLogs(e *env, args []string) error {
+	flags := newFlagSet(e, logsCommand)
+	taskRef := flags.String("task", "", "Only show executions of this task (ID, ID prefix or name)")
+	sinceValue := flags.String("since", "", `Only show executions after this time: a duration such as "24h" or a date/RFC 3339 time`)
+	follow := flags.BoolP("follow", "F", false, "Keep printing new executions as they happen")
+	full := flags.Bool("full", false, "Print the full output of each execution instead of its first line")
+	out := addOutputFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return usagef("unexpected argument %q", flags.Arg(0))
+	}
+
+	var since time.Time
+	if *sinceValue != "" {
+		var err error
+		if since, err = parseSince(*sinceValue, time.Now()); err != nil {
+			return usageError{err}
+		}
+	}
+
+	fetch := e.client.GetLogs
+	if *taskRef != "" {
+		task, err := resolveTask(e.client, *taskRef)
+		if err != nil {
+			return err
+		}
+		fetch = func() ([]api.LogEntry, error) { return e.client.GetTaskLogs(task.ID) }
+	}
+
+	logs, err := fetch()
+	if err != nil {
+		return err
+	}
+	logs = filterSince(logs, since)
+
+	if !*follow {
+		if out.structured() {
+			return out.write(e, logs)
+		}
+		return printLogs(e, logs, *full, true)
+	}
+
+	// Following prints one record per line (JSON Lines) or one YAML
+	// document per execution, so consumers can stream them
+	seen := make(map[string]bool)
+	emit := func(entries []api.LogEntry, header bool) error {
+		var fresh []api.LogEntry
+		for _, entry := range entries {
+			if !seen[entry.ID] {
+				seen[entry.ID] = true
+				fresh = append(fresh, entry)
+			}
+		}
+		switch {
+		case out.json:
+			for _, entry := range fresh {
+				data, err := json.Marshal(entry)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(e.stdout, string(data))
+			}
+			return nil
+		case out.yaml:
+			for _, entry := range fresh {
+				fmt.Fprintln(e.stdout, "---")
+				if err := out.write(e, entry); err != nil {
+					return err
+				}
+			}
+			return nil
+		default:
+			return printLogs(e, fresh, *full, header)
+		}
+	}
+
+	if err := emit(logs, true); err != nil {
+		return err
+	}
+	for {
+		time.Sleep(followInterval)
+
+		logs, err := fetch()
+		if err != nil {
+			// Keep following through transient server restarts
+			fmt.Fprintf(e.stderr, "ritual logs: %v (retrying)\n", err)
+			continue
+		}
+		if err := emit(filterSince(logs, since), false); err != nil {
+			return err
+		}
+	}
+}
+
+// parseSince accepts a duration ago ("90m", "24h", "7d") or an absolute
+// date or time
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if d, err := time.ParseDuration(days + "h"); err == nil {
+			return now.Add(-24 * d), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: use a duration like 24h or 7d, or a date like 2006-01-02", value)
+}
+
+// filterSince drops entries before since and orders the rest oldest
+// first, the natural order for a log
+func filterSince(logs []api.LogEntry, since time.Time) []api.LogEntry {
+	kept := logs[:0:0]
+	for _, entry := range logs {
+		if !entry.ExecutedAt.Before(since) {
+			kept = append(kept, entry)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].ExecutedAt.Before(kept[j].ExecutedAt) })
+	return kept
+}
+
+func printLogs(e *env, logs []api.LogEntry, full, header bool) error {
+	if full {
+		for _, entry := range logs {
+			fmt.Fprintf(e.stdout, "=== %s  %s  %s  %s\n", entry.ExecutedAt.Local().Format("2006-01-02 15:04:05"),
+				entry.TaskName, entry.Status, time.Duration(entry.Duration)*time.Millisecond)
+			if entry.Error != "" {
+				fmt.Fprintf(e.stdout, "error: %s\n", entry.Error)
+			}
+			fmt.Fprintf(e.stdout, "%s\n\n", entry.Output)
+		}
+		return nil
+	}
+
+	rows := make([][]string, len(logs))
+	for i, entry := range logs {
+		summary := entry.Output
+		if entry.Error != "" {
+			summary = entry.Error
+		}
+		rows[i] = []string{
+			entry.ExecutedAt.Local().Format("2006-01-02 15:04:05"),
+			entry.TaskName,
+			entry.Status,
+			(time.Duration(entry.Duration) * time.Millisecond).String(),
+			firstLine(summary, 60),
+		}
+	}
+
+	if !header {
+		return table(e, nil, rows)
+	}
+	return table(e, []string{"TIME", "TASK", "STATUS", "DURATION", "OUTPUT"}, rows)
+}
@@ -0,0 +1,153 @@
+// ABOUTME: Output helpers shared by CLI commands
+// ABOUTME: Human tables by default, or --json/--yaml for scripts
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/components/common"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// outputOptions holds the --json/--yaml flags
+type outputOptions struct {
+	json bool
+	yaml bool
+}
+
+func addOutputFlags(flags *pflag.FlagSet) *outputOptions {
+	opts := &outputOptions{}
+	flags.BoolVar(&opts.json, "json", false, "Print JSON")
+	flags.BoolVar(&opts.yaml, "yaml", false, "Print YAML")
+	return opts
+}
+
+func (o *outputOptions) validate() error {
+	if o.json && o.yaml {
+		return usagef("--json and --yaml are mutually exclusive")
+	}
+	return nil
+}
+
+// structured reports whether a machine-readable format was requested
+func (o *outputOptions) structured() bool {
+	return o.json || o.yaml
+}
+
+// write prints v in the requested structured format. YAML keys follow the
+// API's JSON field names rather than Go's.
+func (o *outputOptions) write(e *env, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if o.json {
+		_, err = fmt.Fprintln(e.stdout, string(data))
+		return err
+	}
+
+	// JSON is valid YAML, so decoding it into a node keeps the key order
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+	enc := yaml.NewEncoder(e.stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// table writes aligned columns, with a header row unless header is nil
+func table(e *env, header []string, rows [][]string) error {
+	w := tabwriter.NewWriter(e.stdout, 0, 0, 2, ' ', 0)
+	if len(header) > 0 {
+		fmt.Fprintln(w, strings.Join(header, "\t"))
+	}
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// shortID abbreviates task IDs for tables; any unique prefix is accepted
+// wherever a task is referenced
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+func relative(t time.Time) string {
+	if t.IsZero() {
+		return "—"
+	}
+	return common.RelativeTime(t, time.Now())
+}
+
+// firstLine returns s up to its first newline, truncated to max runes
+func firstLine(s string, max int) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i] + " …"
+	}
+	if r := []rune(s); len(r) > max {
+		s = string(r[:max-1]) + "…"
+	}
+	return s
+}
+
+// notFoundError is returned when a task reference matches nothing
+type notFoundError struct {
+	ref string
+}
+
+func (e notFoundError) Error() string {
+	return fmt.Sprintf("no task matches %q", e.ref)
+}
+
+// resolveTask finds a task by exact ID, exact name or unique ID prefix
+func resolveTask(client *api.Client, ref string) (api.Task, error) {
+	tasks, err := client.GetTasks()
+	if err != nil {
+		return api.Task{}, err
+	}
+
+	var byPrefix []api.Task
+	for _, task := range tasks {
+		if task.ID == ref || task.Name == ref {
+			return task, nil
+		}
+		if strings.HasPrefix(task.ID, ref) {
+			byPrefix = append(byPrefix, task)
+		}
+	}
+
+	switch len(byPrefix) {
+	case 0:
+		return api.Task{}, notFoundError{ref}
+	case 1:
+		return byPrefix[0], nil
+	default:
+		return api.Task{}, fmt.Errorf("%q matches %d tasks; use more of the ID", ref, len(byPrefix))
+	}
+}
+
+// blockStyle clears the flow style yaml.v3 keeps from JSON input, and the
+// quoting of plain strings, so the output reads like hand-written YAML
+func blockStyle(node *yaml.Node) {
+	node.Style &^= yaml.FlowStyle | yaml.DoubleQuotedStyle
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
@@ -0,0 +1,426 @@
+// ABOUTME: ritual tasks subcommands for scripting task management
+// ABOUTME: list, show, create, update, delete, pause, resume and run
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/schedule"
+	"github.com/spf13/pflag"
+)
+
+var tasksCommand = command{
+	name:    "tasks",
+	args:    "<list|show|create|update|delete|pause|resume|run>",
+	summary: "Manage tasks without the UI",
+}
+
+// taskSubcommands are dispatched by "ritual tasks <name>"
+var taskSubcommands = []command{
+	{name: "list", args: "[--json|--yaml]", summary: "List all tasks"},
+	{name: "show", args: "<task> [--json|--yaml]", summary: "Show one task in full"},
+	{name: "create", args: "--name <name> --prompt <text> --schedule <expr> [flags]", summary: "Create a task"},
+	{name: "update", args: "<task> [flags]", summary: "Change the given fields of a task"},
+	{name: "delete", args: "<task>", summary: "Delete a task"},
+	{name: "pause", args: "<task>", summary: "Pause a task"},
+	{name: "resume", args: "<task>", summary: "Resume a paused task"},
+	{name: "run", args: "<task>", summary: "Run a task now, regardless of its schedule"},
+}
+
+func init() {
+	runners := map[string]func(*env, command, []string) error{
+		"list":   runTasksList,
+		"show":   runTasksShow,
+		"create": runTasksCreate,
+		"update": runTasksUpdate,
+		"delete": runTasksDelete,
+		"pause":  func(e *env, c command, args []string) error { return setTaskStatus(e, c, args, "PAUSED") },
+		"resume": func(e *env, c command, args []string) error { return setTaskStatus(e, c, args, "ACTIVE") },
+		"run":    runTasksRun,
+	}
+
+	tasksCommand.run = func(e *env, args []string) error {
+		if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+			tasksUsage(e.stderr)
+			if len(args) == 0 {
+				return usagef("missing subcommand")
+			}
+			return pflag.ErrHelp
+		}
+
+		for _, sub := range taskSubcommands {
+			if sub.name == args[0] {
+				sub.name = "tasks " + sub.name
+				return runners[args[0]](e, sub, args[1:])
+			}
+		}
+		return usagef("unknown subcommand %q", args[0])
+	}
+	register(tasksCommand)
+}
+
+func tasksUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: ritual tasks <subcommand> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "<task> is a task ID, a unique ID prefix or the exact name.")
+	fmt.Fprintln(w)
+	for _, sub := range taskSubcommands {
+		fmt.Fprintf(w, "  %-8s %s\n", sub.name, sub.summary)
+	}
+}
+
+// taskArg parses flags and returns the single <task> argument
+func taskArg(flags *pflag.FlagSet, args []string) (string, error) {
+	if err := parseFlags(flags, args); err != nil {
+		return "", err
+	}
+	if flags.NArg() != 1 || flags.Arg(0) == "" {
+		return "", usagef("expected one task ID or name")
+	}
+	return flags.Arg(0), nil
+}
+
+func runTasksList(e *env, c command, args []string) error {
+	flags := newFlagSet(e, c)
+	out := addOutputFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+
+	tasks, err := e.client.GetTasks()
+	if err != nil {
+		return err
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Name < tasks[j].Name })
+
+	if out.structured() {
+		return out.write(e, tasks)
+	}
+
+	rows := make([][]string, len(tasks))
+	for i, task := range tasks {
+		rows[i] = []string{shortID(task.ID), task.Name, task.Status, task.Schedule, relative(task.NextRun), relative(task.LastRun)}
+	}
+	return table(e, []string{"ID", "NAME", "STATUS", "SCHEDULE", "NEXT RUN", "LAST RUN"}, rows)
+}
+
+func runTasksShow(e *env, c command, args []string) error {
+	flags := newFlagSet(e, c)
+	out := addOutputFlags(flags)
+	ref, err := taskArg(flags, args)
+	if err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+
+	task, err := resolveTask(e.client, ref)
+	if err != nil {
+		return err
+	}
+
+	if out.structured() {
+		return out.write(e, task)
+	}
+
+	rows := [][2]string{
+		{"ID", task.ID},
+		{"Name", task.Name},
+		{"Status", task.Status},
+		{"Schedule", describeSchedule(task.Schedule)},
+		{"Model", task.Model},
+		{"Output", task.Output},
+		{"Next run", formatTime(task.NextRun)},
+		{"Last run", formatTime(task.LastRun)},
+		{"Managed by", task.ManagedBy},
+	}
+	for _, row := range rows {
+		if row[1] != "" {
+			fmt.Fprintf(e.stdout, "%-11s %s\n", row[0]+":", row[1])
+		}
+	}
+
+	names := make([]string, 0, len(task.Variables))
+	for name := range task.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(e.stdout, "%-11s %s=%s\n", "Variable:", name, task.Variables[name])
+	}
+
+	fmt.Fprintf(e.stdout, "\n%s\n", task.Prompt)
+	return nil
+}
+
+// taskFlags are the editable fields shared by create and update
+type taskFlags struct {
+	name, prompt, promptFile, schedule, model, output string
+	vars                                              map[string]string
+	paused                                            bool
+}
+
+func addTaskFlags(flags *pflag.FlagSet) *taskFlags {
+	f := &taskFlags{}
+	flags.StringVar(&f.name, "name", "", "Task name")
+	flags.StringVar(&f.prompt, "prompt", "", "Prompt text")
+	flags.StringVar(&f.promptFile, "prompt-file", "", "Read the prompt from a file, or - for stdin")
+	flags.StringVar(&f.schedule, "schedule", "", `Schedule, e.g. "daily at 9:00 am" or "0 9 * * 1-5"`)
+	flags.StringVar(&f.model, "model", "", "Model name")
+	flags.StringVar(&f.output, "output", "", "Output destination")
+	flags.StringToStringVar(&f.vars, "var", nil, "Template variable as name=value (repeatable)")
+	flags.BoolVar(&f.paused, "paused", false, "Create or leave the task paused")
+	return f
+}
+
+// apply copies the flags the user set onto task
+func (f *taskFlags) apply(e *env, flags *pflag.FlagSet, task *api.Task) error {
+	if flags.Changed("prompt") && flags.Changed("prompt-file") {
+		return usagef("--prompt and --prompt-file are mutually exclusive")
+	}
+
+	if flags.Changed("name") {
+		task.Name = f.name
+	}
+	if flags.Changed("prompt") {
+		task.Prompt = f.prompt
+	}
+	if flags.Changed("prompt-file") {
+		prompt, err := readInput(e, f.promptFile)
+		if err != nil {
+			return err
+		}
+		task.Prompt = strings.TrimRight(string(prompt), "\n")
+	}
+	if flags.Changed("model") {
+		task.Model = f.model
+	}
+	if flags.Changed("output") {
+		task.Output = f.output
+	}
+	if flags.Changed("var") {
+		if task.Variables == nil {
+			task.Variables = make(map[string]string)
+		}
+		for name, value := range f.vars {
+			task.Variables[name] = value
+		}
+	}
+	if flags.Changed("paused") {
+		task.Status = "ACTIVE"
+		if f.paused {
+			task.Status = "PAUSED"
+		}
+	}
+	if flags.Changed("schedule") {
+		parsed, err := schedule.Parse(f.schedule)
+		if err != nil {
+			return usageError{err}
+		}
+		task.Schedule = parsed.Expr
+		task.NextRun = parsed.Next(time.Now())
+	}
+	return nil
+}
+
+// patch returns the changes the flags the user set make to task, so an
+// update sends only those fields
+func (f *taskFlags) patch(e *env, flags *pflag.FlagSet, task api.Task) (api.TaskPatch, error) {
+	changed := task
+	changed.Variables = maps.Clone(task.Variables)
+	if err := f.apply(e, flags, &changed); err != nil {
+		return api.TaskPatch{}, err
+	}
+
+	patch := api.Patch()
+	if flags.Changed("name") {
+		patch = patch.Name(changed.Name)
+	}
+	if flags.Changed("prompt") || flags.Changed("prompt-file") {
+		patch = patch.Prompt(changed.Prompt)
+	}
+	if flags.Changed("model") {
+		patch = patch.Model(changed.Model)
+	}
+	if flags.Changed("output") {
+		patch = patch.Output(changed.Output)
+	}
+	if flags.Changed("var") {
+		patch = patch.Variables(changed.Variables)
+	}
+	if flags.Changed("paused") {
+		patch = patch.Status(changed.Status)
+	}
+	if flags.Changed("schedule") {
+		patch = patch.Schedule(changed.Schedule)
+	}
+	return patch, nil
+}
+
+func runTasksCreate(e *env, c command, args []string) error {
+	flags := newFlagSet(e, c)
+	fields := addTaskFlags(flags)
+	out := addOutputFlags(flags)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return usagef("unexpected argument %q", flags.Arg(0))
+	}
+
+	task := api.Task{Status: "ACTIVE"}
+	if err := fields.apply(e, flags, &task); err != nil {
+		return err
+	}
+	switch {
+	case strings.TrimSpace(task.Name) == "":
+		return usagef("--name is required")
+	case strings.TrimSpace(task.Prompt) == "":
+		return usagef("--prompt or --prompt-file is required")
+	case task.Schedule == "":
+		return usagef("--schedule is required")
+	}
+
+	created, err := e.client.CreateTask(task)
+	if err != nil {
+		return err
+	}
+
+	if out.structured() {
+		return out.write(e, created)
+	}
+	fmt.Fprintln(e.stdout, created.ID)
+	return nil
+}
+
+func runTasksUpdate(e *env, c command, args []string) error {
+	flags := newFlagSet(e, c)
+	fields := addTaskFlags(flags)
+	out := addOutputFlags(flags)
+	ref, err := taskArg(flags, args)
+	if err != nil {
+		return err
+	}
+	if err := out.validate(); err != nil {
+		return err
+	}
+	if flags.NFlag() == btoi(out.json)+btoi(out.yaml) {
+		return usagef("nothing to update; pass at least one field flag")
+	}
+
+	task, err := resolveTask(e.client, ref)
+	if err != nil {
+		return err
+	}
+	patch, err := fields.patch(e, flags, task)
+	if err != nil {
+		return err
+	}
+
+	updated, err := e.client.PatchTask(task.ID, patch.IfMatch(task.ETag()))
+	if err != nil {
+		return err
+	}
+
+	if out.structured() {
+		return out.write(e, updated)
+	}
+	fmt.Fprintf(e.stdout, "Updated %s (%s)\n", updated.Name, shortID(updated.ID))
+	return nil
+}
+
+func runTasksDelete(e *env, c command, args []string) error {
+	ref, err := taskArg(newFlagSet(e, c), args)
+	if err != nil {
+		return err
+	}
+
+	task, err := resolveTask(e.client, ref)
+	if err != nil {
+		return err
+	}
+	if err := e.client.DeleteTask(task.ID); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(e.stdout, "Deleted %s (%s)\n", task.Name, shortID(task.ID))
+	return nil
+}
+
+func setTaskStatus(e *env, c command, args []string, status string) error {
+	ref, err := taskArg(newFlagSet(e, c), args)
+	if err != nil {
+		return err
+	}
+
+	task, err := resolveTask(e.client, ref)
+	if err != nil {
+		return err
+	}
+	if task.Status == status {
+		fmt.Fprintf(e.stdout, "%s is already %s\n", task.Name, strings.ToLower(status))
+		return nil
+	}
+
+	if _, err := e.client.PatchTask(task.ID, api.Patch().Status(status).IfMatch(task.ETag())); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(e.stdout, "%s is now %s\n", task.Name, strings.ToLower(status))
+	return nil
+}
+
+func runTasksRun(e *env, c command, args []string) error {
+	ref, err := taskArg(newFlagSet(e, c), args)
+	if err != nil {
+		return err
+	}
+
+	task, err := resolveTask(e.client, ref)
+	if err != nil {
+		return err
+	}
+	if err := e.client.RunTask(task.ID); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(e.stdout, "Queued %s; follow it with: ritual logs --follow --task %s\n", task.Name, shortID(task.ID))
+	return nil
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func describeSchedule(expr string) string {
+	parsed, err := schedule.Parse(expr)
+	if err != nil {
+		return expr
+	}
+	return fmt.Sprintf("%s (%s)", expr, parsed.Description)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	local := t.Local()
+	return fmt.Sprintf("%s (%s)", local.Format(time.RFC3339), relative(local))
+}
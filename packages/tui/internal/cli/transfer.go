@@ -0,0 +1,180 @@
+// ABOUTME: ritual export and ritual import commands
+// ABOUTME: Moves rituals between servers as versioned YAML or JSON documents
+
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/ritualfile"
+)
+
+var exportCommand = command{
+	name:    "export",
+	args:    "[--ids id,...] [--format yaml|json]",
+	summary: "Write rituals to stdout as a versioned YAML or JSON document",
+}
+
+var importCommand = command{
+	name:    "import",
+	args:    "[--on-duplicate skip|overwrite|rename] <file|->",
+	summary: "Create rituals from an exported document",
+}
+
+func init() {
+	exportCommand.run = runExport
+	importCommand.run = runImport
+	register(exportCommand)
+	register(importCommand)
+}
+
+func runExport(e *env, args []string) error {
+	flags := newFlagSet(e, exportCommand)
+	ids := flags.StringSlice("ids", nil, "Only export the tasks with these IDs")
+	formatName := flags.StringP("format", "f", "yaml", "Output format: yaml or json")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return usagef("unexpected argument %q", flags.Arg(0))
+	}
+
+	format, err := ritualfile.ParseFormat(*formatName)
+	if err != nil {
+		return usageError{err}
+	}
+
+	tasks, err := e.client.GetTasks()
+	if err != nil {
+		return err
+	}
+
+	if len(*ids) > 0 {
+		tasks, err = selectTasks(tasks, *ids)
+		if err != nil {
+			return err
+		}
+	}
+
+	return ritualfile.Encode(e.stdout, ritualfile.FromTasks(tasks), format)
+}
+
+// selectTasks keeps the tasks with the given IDs, in the order requested
+func selectTasks(tasks []api.Task, ids []string) ([]api.Task, error) {
+	byID := make(map[string]api.Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	selected := make([]api.Task, 0, len(ids))
+	for _, id := range ids {
+		task, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("no task with ID %q", id)
+		}
+		selected = append(selected, task)
+	}
+	return selected, nil
+}
+
+func runImport(e *env, args []string) error {
+	flags := newFlagSet(e, importCommand)
+	onDuplicate := flags.String("on-duplicate", "", "What to do when a name already exists: skip, overwrite or rename (asks when interactive)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usagef("expected one file argument, or - for stdin")
+	}
+
+	data, err := readInput(e, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	doc, err := ritualfile.Decode(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", flags.Arg(0), err)
+	}
+
+	var resolve ritualfile.Resolver
+	switch {
+	case *onDuplicate != "":
+		strategy, err := ritualfile.ParseStrategy(*onDuplicate)
+		if err != nil {
+			return usageError{err}
+		}
+		resolve = ritualfile.Always(strategy)
+	case flags.Arg(0) != "-" && isTerminal(e.stdin):
+		resolve = askStrategy(e)
+	default:
+		// Refuse up front rather than failing halfway through the import
+		existing, err := e.client.GetTasks()
+		if err != nil {
+			return err
+		}
+		if dups := ritualfile.Duplicates(doc, existing); len(dups) > 0 {
+			return usagef("%d rituals already exist (first: %q); pass --on-duplicate skip, overwrite or rename",
+				len(dups), dups[0].Name)
+		}
+		resolve = ritualfile.Always(ritualfile.Skip)
+	}
+
+	result, err := ritualfile.Import(e.client, doc, resolve)
+	for _, name := range result.Renamed {
+		fmt.Fprintf(e.stderr, "renamed %s\n", name)
+	}
+	fmt.Fprintf(e.stdout, "%s: %s\n", flags.Arg(0), result)
+	return err
+}
+
+func readInput(e *env, path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(e.stdin)
+	}
+	return os.ReadFile(path)
+}
+
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	return ok && term.IsTerminal(f.Fd())
+}
+
+// askStrategy prompts for each duplicate, with an option to apply the
+// answer to all remaining ones
+func askStrategy(e *env) ritualfile.Resolver {
+	in := bufio.NewReader(e.stdin)
+	var always ritualfile.Strategy
+
+	return func(r ritualfile.Ritual, _ api.Task) (ritualfile.Strategy, error) {
+		if always != "" {
+			return always, nil
+		}
+
+		for {
+			fmt.Fprintf(e.stderr, "%q already exists. [s]kip, [o]verwrite, [r]ename (capital letter = all): ", r.Name)
+			line, err := in.ReadString('\n')
+			if err != nil {
+				return "", fmt.Errorf("reading answer: %w", err)
+			}
+
+			answer := strings.TrimSpace(line)
+			strategy, ok := map[string]ritualfile.Strategy{
+				"s": ritualfile.Skip, "o": ritualfile.Overwrite, "r": ritualfile.Rename,
+			}[strings.ToLower(answer)]
+			if !ok {
+				continue
+			}
+			if answer != strings.ToLower(answer) {
+				always = strategy
+			}
+			return strategy, nil
+		}
+	}
+}
@@ -0,0 +1,390 @@
+// ABOUTME: Generates the Go SDK's types and client methods from the server's OpenAPI spec
+// ABOUTME: Supports the parts of OpenAPI the Ritual API uses and rejects anything else
+
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Options controls the generated file
+type Options struct {
+	Package string // the Go package name
+	Source  string // the spec's path, named in the file header
+}
+
+// Generate returns the Go source for the spec: a type per component
+// schema and a Client method per operation. The methods rely on the
+// package providing Client and its do method; see packages/tui/sdk.
+//
+// Schemas map to Go as follows: required properties are plain values,
+// with null decoding to the zero value; optional ones are pointers omitted
+// when nil, unless marked x-go-type-skip-optional-pointer; string enums
+// become a string alias with a constant per value.
+func Generate(spec []byte, opts Options) ([]byte, error) {
+	var doc document
+	if err := yaml.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("parsing spec: %w", err)
+	}
+
+	g := &generator{doc: &doc, imports: map[string]bool{}}
+	for _, e := range doc.Components.Schemas {
+		if err := g.schema(e.key, e.value); err != nil {
+			return nil, fmt.Errorf("schema %s: %w", e.key, err)
+		}
+	}
+	for _, path := range doc.Paths {
+		for _, op := range path.value.operations() {
+			if err := g.operation(path.key, path.value, op.method, op.op); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", op.method, path.key, err)
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// ABOUTME: Ritual API types and client methods generated from the OpenAPI spec\n")
+	fmt.Fprintf(&out, "// ABOUTME: Regenerate with go generate after changing the spec rather than editing this file\n\n")
+	fmt.Fprintf(&out, "// Code generated by sdkgen from %s. DO NOT EDIT.\n\n", opts.Source)
+	fmt.Fprintf(&out, "package %s\n\n", opts.Package)
+
+	imports := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		imports = append(imports, strconv.Quote(path))
+	}
+	sort.Strings(imports)
+	fmt.Fprintf(&out, "import (\n%s\n)\n", strings.Join(imports, "\n"))
+	out.Write(g.types.Bytes())
+	out.Write(g.methods.Bytes())
+
+	formatted, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return formatted, nil
+}
+
+type generator struct {
+	doc     *document
+	imports map[string]bool
+	types   bytes.Buffer
+	methods bytes.Buffer
+}
+
+// schema writes the Go type for a component schema
+func (g *generator) schema(name string, s *schema) error {
+	w := &g.types
+	fmt.Fprintln(w)
+	writeDoc(w, name+" is "+lowerFirst(s.Description))
+
+	switch {
+	case s.Type == "string" && len(s.Enum) > 0:
+		fmt.Fprintf(w, "type %s = string\n\nconst (\n", name)
+		for _, value := range s.Enum {
+			fmt.Fprintf(w, "\t%s%s %s = %q\n", name, goName(strings.ToLower(value)), name, value)
+		}
+		fmt.Fprintln(w, ")")
+		return nil
+
+	case s.Type == "object" && len(s.Properties) > 0:
+		fmt.Fprintf(w, "type %s struct {\n", name)
+		for _, p := range s.Properties {
+			typ, err := g.goType(p.value)
+			if err != nil {
+				return fmt.Errorf("property %s: %w", p.key, err)
+			}
+
+			tag := p.key
+			switch {
+			case s.required(p.key):
+			case p.value.SkipOptionalPointer:
+				tag += ",omitempty"
+			default:
+				typ = "*" + typ
+				tag += ",omitempty"
+			}
+
+			if p.value.Description != "" {
+				writeDoc(w, p.value.Description)
+			}
+			fmt.Fprintf(w, "%s %s `json:%q`\n", goName(p.key), typ, tag)
+		}
+		fmt.Fprintln(w, "}")
+		return nil
+	}
+	return fmt.Errorf("only string enums and objects with properties can be components")
+}
+
+// goType returns the Go type for a property or item schema
+func (g *generator) goType(s *schema) (string, error) {
+	if s.Ref != "" {
+		return schemaName(s.Ref)
+	}
+
+	switch s.Type {
+	case "string":
+		if s.Format == "date-time" {
+			g.imports["time"] = true
+			return "time.Time", nil
+		}
+		return "string", nil
+	case "integer":
+		switch s.Format {
+		case "int32", "int64":
+			return s.Format, nil
+		}
+		return "int", nil
+	case "number":
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		if s.Items == nil {
+			return "", fmt.Errorf("array without items")
+		}
+		item, err := g.goType(s.Items)
+		return "[]" + item, err
+	case "object":
+		if s.AdditionalProperties == nil || len(s.Properties) > 0 {
+			return "", fmt.Errorf("inline objects must be maps; declare a component instead")
+		}
+		value, err := g.goType(s.AdditionalProperties)
+		return "map[string]" + value, err
+	}
+	return "", fmt.Errorf("unsupported type %q", s.Type)
+}
+
+// operation writes the Client method for an operation, with a params
+// struct for its header parameters when it has any
+func (g *generator) operation(path string, item *pathItem, method string, op *operation) error {
+	if op.OperationID == "" {
+		return fmt.Errorf("missing operationId")
+	}
+	name := goName(op.OperationID)
+
+	var pathParams, headerParams []*parameter
+	for _, p := range append(append([]*parameter(nil), item.Parameters...), op.Parameters...) {
+		p, err := g.doc.resolveParameter(p)
+		if err != nil {
+			return err
+		}
+		if p.Schema == nil || p.Schema.Type != "string" {
+			return fmt.Errorf("parameter %s: only string parameters are supported", p.Name)
+		}
+		switch p.In {
+		case "path":
+			pathParams = append(pathParams, p)
+		case "header":
+			headerParams = append(headerParams, p)
+		default:
+			return fmt.Errorf("parameter %s: %s parameters aren't supported", p.Name, p.In)
+		}
+	}
+
+	args := []string{"ctx context.Context"}
+	g.imports["context"] = true
+	g.imports["net/http"] = true
+	for _, p := range pathParams {
+		args = append(args, lowerFirst(goName(p.Name))+" string")
+	}
+
+	body := "nil"
+	if op.RequestBody != nil {
+		media, ok := op.RequestBody.Content["application/json"]
+		if !ok || media.Schema == nil {
+			return fmt.Errorf("request body must be application/json")
+		}
+		typ, err := g.goType(media.Schema)
+		if err != nil {
+			return fmt.Errorf("request body: %w", err)
+		}
+		args = append(args, "body "+typ)
+		body = "body"
+	}
+
+	if len(headerParams) > 0 {
+		params := name + "Params"
+		w := &g.types
+		fmt.Fprintf(w, "\n// %s are the optional headers of %s\ntype %s struct {\n", params, name, params)
+		for _, p := range headerParams {
+			if p.Description != "" {
+				writeDoc(w, p.Description)
+			}
+			fmt.Fprintf(w, "%s string\n", goName(p.Name))
+		}
+		fmt.Fprintln(w, "}")
+		args = append(args, "params *"+params)
+	}
+
+	status, result, err := g.success(op)
+	if err != nil {
+		return err
+	}
+
+	w := &g.methods
+	fmt.Fprintln(w)
+	writeDoc(w, name+" "+lowerFirst(op.Summary))
+	switch {
+	case result == "":
+		fmt.Fprintf(w, "func (c *Client) %s(%s) error {\n", name, strings.Join(args, ", "))
+	case strings.HasPrefix(result, "[]"):
+		fmt.Fprintf(w, "func (c *Client) %s(%s) (%s, error) {\n", name, strings.Join(args, ", "), result)
+	default:
+		fmt.Fprintf(w, "func (c *Client) %s(%s) (*%s, error) {\n", name, strings.Join(args, ", "), result)
+	}
+
+	header := "nil"
+	if len(headerParams) > 0 {
+		header = "header"
+		fmt.Fprintf(w, "header := http.Header{}\nif params != nil {\n")
+		for _, p := range headerParams {
+			field := goName(p.Name)
+			fmt.Fprintf(w, "if params.%s != \"\" {\nheader.Set(%q, params.%s)\n}\n", field, p.Name, field)
+		}
+		fmt.Fprintln(w, "}")
+	}
+
+	urlPath, err := g.pathExpr(path, pathParams)
+	if err != nil {
+		return err
+	}
+	call := fmt.Sprintf("c.do(ctx, %s, %s, %s, %s, %s", methodConst(method), urlPath, header, body, statusConst(status))
+
+	switch {
+	case result == "":
+		fmt.Fprintf(w, "return %s, nil)\n}\n", call)
+	case strings.HasPrefix(result, "[]"):
+		fmt.Fprintf(w, "var out %s\nif err := %s, &out); err != nil {\nreturn nil, err\n}\nreturn out, nil\n}\n", result, call)
+	default:
+		fmt.Fprintf(w, "var out %s\nif err := %s, &out); err != nil {\nreturn nil, err\n}\nreturn &out, nil\n}\n", result, call)
+	}
+	return nil
+}
+
+// success returns the operation's success status and the Go type of its
+// JSON body, or "" when it has none
+func (g *generator) success(op *operation) (int, string, error) {
+	for _, r := range op.Responses {
+		status, err := strconv.Atoi(r.key)
+		if err != nil || status < 200 || status > 299 {
+			continue
+		}
+		media, ok := r.value.Content["application/json"]
+		if !ok || media.Schema == nil {
+			return status, "", nil
+		}
+		typ, err := g.goType(media.Schema)
+		return status, typ, err
+	}
+	return 0, "", fmt.Errorf("no 2xx response")
+}
+
+// pathExpr returns a Go expression building path with its parameters
+// escaped into it
+func (g *generator) pathExpr(path string, params []*parameter) (string, error) {
+	var parts []string
+	rest := path
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(rest, '}')
+		if end < start {
+			return "", fmt.Errorf("malformed path")
+		}
+
+		name := rest[start+1 : end]
+		found := false
+		for _, p := range params {
+			found = found || p.Name == name
+		}
+		if !found {
+			return "", fmt.Errorf("path parameter %s isn't declared", name)
+		}
+
+		g.imports["net/url"] = true
+		if start > 0 {
+			parts = append(parts, strconv.Quote(rest[:start]))
+		}
+		parts = append(parts, "url.PathEscape("+lowerFirst(goName(name))+")")
+		rest = rest[end+1:]
+	}
+	if rest != "" {
+		parts = append(parts, strconv.Quote(rest))
+	}
+	return strings.Join(parts, "+"), nil
+}
+
+// goName turns a JSON or header name such as "taskId" or "If-Match" into
+// an exported Go identifier such as "TaskID" or "IfMatch"
+func goName(name string) string {
+	var words []string
+	start := 0
+	runes := []rune(name)
+	for i := 1; i <= len(runes); i++ {
+		if i == len(runes) || runes[i] == '-' || runes[i] == '_' || unicode.IsUpper(runes[i]) {
+			if word := strings.Trim(string(runes[start:i]), "-_"); word != "" {
+				words = append(words, word)
+			}
+			start = i
+		}
+	}
+
+	var b strings.Builder
+	for _, word := range words {
+		switch upper := strings.ToUpper(word); upper {
+		case "ID", "URL", "HTTP", "JSON", "API":
+			b.WriteString(upper)
+		default:
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return b.String()
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	if upper := strings.ToUpper(s); strings.HasPrefix(upper, "ID") && (len(s) == 2 || !unicode.IsLower(rune(s[2]))) {
+		return strings.ToLower(s[:2]) + s[2:]
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// writeDoc writes text as a doc comment
+func writeDoc(w *bytes.Buffer, text string) {
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		fmt.Fprintf(w, "// %s\n", strings.TrimSpace(line))
+	}
+}
+
+func methodConst(method string) string {
+	return "http.Method" + method[:1] + strings.ToLower(method[1:])
+}
+
+func statusConst(status int) string {
+	for _, name := range []struct {
+		status int
+		name   string
+	}{
+		{http.StatusOK, "StatusOK"},
+		{http.StatusCreated, "StatusCreated"},
+		{http.StatusAccepted, "StatusAccepted"},
+		{http.StatusNoContent, "StatusNoContent"},
+	} {
+		if name.status == status {
+			return "http." + name.name
+		}
+	}
+	return strconv.Itoa(status)
+}
@@ -0,0 +1,145 @@
+// ABOUTME: The subset of OpenAPI 3.0 the SDK generator reads
+// ABOUTME: Keeps paths, schemas and properties in document order so the output is stable
+
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// document is an OpenAPI document, as far as the generator needs it
+type document struct {
+	Paths      ordered[*pathItem] `yaml:"paths"`
+	Components struct {
+		Schemas    ordered[*schema]      `yaml:"schemas"`
+		Parameters map[string]*parameter `yaml:"parameters"`
+	} `yaml:"components"`
+}
+
+type pathItem struct {
+	Parameters []*parameter `yaml:"parameters"`
+	Get        *operation   `yaml:"get"`
+	Post       *operation   `yaml:"post"`
+	Put        *operation   `yaml:"put"`
+	Patch      *operation   `yaml:"patch"`
+	Delete     *operation   `yaml:"delete"`
+}
+
+// operations returns the path's operations by HTTP method, in a fixed order
+func (p *pathItem) operations() []methodOperation {
+	var ops []methodOperation
+	for _, op := range []methodOperation{
+		{"GET", p.Get}, {"POST", p.Post}, {"PUT", p.Put}, {"PATCH", p.Patch}, {"DELETE", p.Delete},
+	} {
+		if op.op != nil {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+type methodOperation struct {
+	method string
+	op     *operation
+}
+
+type operation struct {
+	OperationID string       `yaml:"operationId"`
+	Summary     string       `yaml:"summary"`
+	Parameters  []*parameter `yaml:"parameters"`
+	RequestBody *struct {
+		Content map[string]mediaType `yaml:"content"`
+	} `yaml:"requestBody"`
+	Responses ordered[*response] `yaml:"responses"`
+}
+
+type response struct {
+	Ref     string               `yaml:"$ref"`
+	Content map[string]mediaType `yaml:"content"`
+}
+
+type mediaType struct {
+	Schema *schema `yaml:"schema"`
+}
+
+type parameter struct {
+	Ref         string  `yaml:"$ref"`
+	Name        string  `yaml:"name"`
+	In          string  `yaml:"in"`
+	Description string  `yaml:"description"`
+	Required    bool    `yaml:"required"`
+	Schema      *schema `yaml:"schema"`
+}
+
+type schema struct {
+	Ref                  string           `yaml:"$ref"`
+	Type                 string           `yaml:"type"`
+	Format               string           `yaml:"format"`
+	Description          string           `yaml:"description"`
+	Nullable             bool             `yaml:"nullable"`
+	Enum                 []string         `yaml:"enum"`
+	Required             []string         `yaml:"required"`
+	Properties           ordered[*schema] `yaml:"properties"`
+	Items                *schema          `yaml:"items"`
+	AdditionalProperties *schema          `yaml:"additionalProperties"`
+
+	// SkipOptionalPointer makes an optional property a plain value that's
+	// omitted when zero, as in oapi-codegen
+	SkipOptionalPointer bool `yaml:"x-go-type-skip-optional-pointer"`
+}
+
+// required reports whether the object schema requires property name
+func (s *schema) required(name string) bool {
+	for _, r := range s.Required {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ordered is a YAML mapping that keeps its keys in document order
+type ordered[T any] []entry[T]
+
+type entry[T any] struct {
+	key   string
+	value T
+}
+
+func (o *ordered[T]) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping", node.Line)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var value T
+		if err := node.Content[i+1].Decode(&value); err != nil {
+			return err
+		}
+		*o = append(*o, entry[T]{key: node.Content[i].Value, value: value})
+	}
+	return nil
+}
+
+// resolveParameter follows a parameter's $ref into the components
+func (d *document) resolveParameter(p *parameter) (*parameter, error) {
+	if p.Ref == "" {
+		return p, nil
+	}
+	name, ok := strings.CutPrefix(p.Ref, "#/components/parameters/")
+	if !ok || d.Components.Parameters[name] == nil {
+		return nil, fmt.Errorf("unknown parameter %s", p.Ref)
+	}
+	return d.Components.Parameters[name], nil
+}
+
+// schemaName returns the component name a schema $ref points to
+func schemaName(ref string) (string, error) {
+	name, ok := strings.CutPrefix(ref, "#/components/schemas/")
+	if !ok {
+		return "", fmt.Errorf("unsupported $ref %s", ref)
+	}
+	return name, nil
+}
@@ -0,0 +1,899 @@
+// ABOUTME: Calendar component showing when rituals fire across days, weeks and months
+// ABOUTME: Projects future fire times from task schedules and overlays past executions from logs
+
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/charmbracelet/lipgloss/v2/compat"
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/components/common"
+	"github.com/jem-computer/ritual/tui/internal/schedule"
+	"github.com/jem-computer/ritual/tui/internal/styles"
+	"github.com/jem-computer/ritual/tui/internal/theme"
+)
+
+type viewMode int
+
+const (
+	viewDay viewMode = iota
+	viewWeek
+	viewMonth
+	viewAgenda
+)
+
+var viewNames = []string{"Day", "Week", "Month", "Agenda"}
+
+const (
+	// maxPerTask caps projected fire times per task and period so
+	// minute-level schedules don't flood the grid
+	maxPerTask = 500
+	// agendaDays is how far ahead the agenda timeline looks
+	agendaDays = 7
+)
+
+// OpenTaskMsg asks the parent to show the given task on the dashboard
+type OpenTaskMsg struct {
+	TaskID string
+}
+
+// occurrence is a single fire time: projected from a schedule when status
+// is empty, or a past execution taken from the logs
+type occurrence struct {
+	taskID   string
+	taskName string
+	at       time.Time
+	status   string
+}
+
+type Model struct {
+	client *api.Client
+	width  int
+	height int
+	keys   keyMap
+	err    error
+
+	mode   viewMode
+	now    time.Time
+	cursor time.Time // start of the selected slot
+	entry  int       // selected occurrence within the slot, or within the agenda
+
+	tasks     []api.Task
+	logs      []api.LogEntry
+	schedules map[string]*schedule.Schedule
+	invalid   []string // names of active tasks whose schedule doesn't parse
+
+	// cancelLoad stops the in-flight load when a reload supersedes it
+	cancelLoad context.CancelFunc
+
+	// reconnecting keeps the last data on screen while the server is away;
+	// it reloads once the connection is back
+	reconnecting bool
+}
+
+type keyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Left   key.Binding
+	Right  key.Binding
+	Prev   key.Binding
+	Next   key.Binding
+	View   key.Binding
+	Today  key.Binding
+	Cycle  key.Binding
+	Open   key.Binding
+	Reload key.Binding
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "earlier"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "later"),
+		),
+		Left: key.NewBinding(
+			key.WithKeys("left"),
+			key.WithHelp("←", "prev day"),
+		),
+		Right: key.NewBinding(
+			key.WithKeys("right"),
+			key.WithHelp("→", "next day"),
+		),
+		Prev: key.NewBinding(
+			key.WithKeys("["),
+			key.WithHelp("[", "prev period"),
+		),
+		Next: key.NewBinding(
+			key.WithKeys("]"),
+			key.WithHelp("]", "next period"),
+		),
+		View: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "day/week/month/agenda"),
+		),
+		Today: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "today"),
+		),
+		Cycle: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "next in slot"),
+		),
+		Open: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "open task"),
+		),
+		Reload: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "reload"),
+		),
+	}
+}
+
+func New(client *api.Client) Model {
+	now := common.Now()
+	m := Model{
+		client:    client,
+		keys:      defaultKeyMap(),
+		mode:      viewWeek,
+		now:       now,
+		schedules: make(map[string]*schedule.Schedule),
+	}
+	m.cursor = m.slotStart(now)
+	return m
+}
+
+func (m Model) Init() (tea.Model, tea.Cmd) {
+	cmd := m.startLoad()
+	return m, cmd
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height - 4 // Account for tab bar
+
+	case tea.KeyMsg:
+		m.now = common.Now()
+
+		switch {
+		case key.Matches(msg, m.keys.View):
+			m.mode = (m.mode + 1) % viewMode(len(viewNames))
+			// Landing on today in an hourly grid selects the current hour
+			if startOfDay(m.cursor).Equal(startOfDay(m.now)) && m.cursor.Hour() == 0 {
+				m.cursor = m.now
+			}
+			m.cursor = m.slotStart(m.cursor)
+			m.entry = 0
+
+		case key.Matches(msg, m.keys.Up):
+			if m.mode == viewAgenda {
+				if m.entry > 0 {
+					m.entry--
+				}
+			} else {
+				m.moveCursor(-1, false)
+			}
+
+		case key.Matches(msg, m.keys.Down):
+			if m.mode == viewAgenda {
+				if m.entry < len(m.agenda())-1 {
+					m.entry++
+				}
+			} else {
+				m.moveCursor(1, false)
+			}
+
+		case key.Matches(msg, m.keys.Left):
+			m.moveCursor(-1, true)
+
+		case key.Matches(msg, m.keys.Right):
+			m.moveCursor(1, true)
+
+		case key.Matches(msg, m.keys.Prev):
+			m.movePeriod(-1)
+
+		case key.Matches(msg, m.keys.Next):
+			m.movePeriod(1)
+
+		case key.Matches(msg, m.keys.Today):
+			m.cursor = m.slotStart(m.now)
+			m.entry = 0
+
+		case key.Matches(msg, m.keys.Cycle):
+			if n := len(m.slotOccurrences()); n > 0 && m.mode != viewAgenda {
+				m.entry = (m.entry + 1) % n
+			}
+
+		case key.Matches(msg, m.keys.Open):
+			if occ, ok := m.selected(); ok {
+				return m, func() tea.Msg { return OpenTaskMsg{TaskID: occ.taskID} }
+			}
+
+		case key.Matches(msg, m.keys.Reload):
+			m.err = nil
+			return m, m.startLoad()
+		}
+
+	case dataLoadedMsg:
+		m.err = nil
+		m.reconnecting = false
+		m.now = common.Now()
+		m.tasks = msg.tasks
+		m.logs = msg.logs
+		m.schedules = make(map[string]*schedule.Schedule)
+		m.invalid = nil
+		for _, task := range m.tasks {
+			s, err := schedule.Parse(task.Schedule)
+			if err != nil {
+				if task.Status == "ACTIVE" {
+					m.invalid = append(m.invalid, task.Name)
+				}
+				continue
+			}
+			m.schedules[task.ID] = s
+		}
+
+	case errorMsg:
+		// A superseded load; its replacement is already on the way
+		if errors.Is(msg.err, context.Canceled) {
+			return m, nil
+		}
+		if common.Transient(msg.err, m.client.ConnState()) {
+			m.reconnecting = true
+			return m, nil
+		}
+		m.err = msg.err
+
+	case common.ConnectionMsg:
+		if msg.State == api.Connected && (m.reconnecting || api.IsUnauthorized(m.err)) {
+			m.err = nil
+			return m, m.startLoad()
+		}
+	}
+
+	return m, nil
+}
+
+// moveCursor steps the selected slot. Horizontal moves are always a day;
+// vertical moves are an hour in the day and week grids and a week in the
+// month grid.
+func (m *Model) moveCursor(delta int, horizontal bool) {
+	switch {
+	case horizontal:
+		m.cursor = m.cursor.AddDate(0, 0, delta)
+	case m.mode == viewMonth:
+		m.cursor = m.cursor.AddDate(0, 0, 7*delta)
+	default:
+		m.cursor = m.cursor.Add(time.Duration(delta) * time.Hour)
+	}
+	m.cursor = m.slotStart(m.cursor)
+	m.entry = 0
+}
+
+func (m *Model) movePeriod(delta int) {
+	switch m.mode {
+	case viewDay, viewAgenda:
+		m.cursor = m.cursor.AddDate(0, 0, delta)
+	case viewWeek:
+		m.cursor = m.cursor.AddDate(0, 0, 7*delta)
+	case viewMonth:
+		m.cursor = m.cursor.AddDate(0, delta, 0)
+	}
+	m.cursor = m.slotStart(m.cursor)
+	m.entry = 0
+}
+
+// Time arithmetic
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func startOfHour(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+}
+
+// startOfWeek returns the Monday starting t's week
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return startOfDay(t).AddDate(0, 0, -offset)
+}
+
+func (m Model) slotStart(t time.Time) time.Time {
+	if m.mode == viewMonth || m.mode == viewAgenda {
+		return startOfDay(t)
+	}
+	return startOfHour(t)
+}
+
+func (m Model) slotEnd(start time.Time) time.Time {
+	if m.mode == viewMonth || m.mode == viewAgenda {
+		return start.AddDate(0, 0, 1)
+	}
+	return start.Add(time.Hour)
+}
+
+// period returns the range covered by the current view
+func (m Model) period() (time.Time, time.Time) {
+	switch m.mode {
+	case viewDay:
+		start := startOfDay(m.cursor)
+		return start, start.AddDate(0, 0, 1)
+	case viewWeek:
+		start := startOfWeek(m.cursor)
+		return start, start.AddDate(0, 0, 7)
+	case viewMonth:
+		start := time.Date(m.cursor.Year(), m.cursor.Month(), 1, 0, 0, 0, 0, m.cursor.Location())
+		return start, start.AddDate(0, 1, 0)
+	default:
+		start := startOfDay(m.cursor)
+		if start.Before(m.now) {
+			start = m.now
+		}
+		return start, startOfDay(m.cursor).AddDate(0, 0, agendaDays)
+	}
+}
+
+// occurrences returns executions from the logs before now and projected
+// fire times of active tasks after now, within [from, to), in time order.
+func (m Model) occurrences(from, to time.Time) []occurrence {
+	var occs []occurrence
+
+	for _, log := range m.logs {
+		at := log.ExecutedAt.Local()
+		if !at.Before(from) && at.Before(to) && at.Before(m.now) {
+			occs = append(occs, occurrence{
+				taskID:   log.TaskID,
+				taskName: log.TaskName,
+				at:       at,
+				status:   log.Status,
+			})
+		}
+	}
+
+	projectFrom := from
+	if projectFrom.Before(m.now) {
+		projectFrom = m.now
+	}
+	if projectFrom.Before(to) {
+		for _, task := range m.tasks {
+			s, ok := m.schedules[task.ID]
+			if !ok || task.Status != "ACTIVE" {
+				continue
+			}
+			for _, at := range s.Between(projectFrom, to, maxPerTask) {
+				occs = append(occs, occurrence{
+					taskID:   task.ID,
+					taskName: task.Name,
+					at:       at,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(occs, func(a, b int) bool {
+		if !occs[a].at.Equal(occs[b].at) {
+			return occs[a].at.Before(occs[b].at)
+		}
+		return occs[a].taskName < occs[b].taskName
+	})
+	return occs
+}
+
+func (m Model) slotOccurrences() []occurrence {
+	return m.occurrences(m.cursor, m.slotEnd(m.cursor))
+}
+
+func (m Model) agenda() []occurrence {
+	from, to := m.period()
+	if m.mode != viewAgenda {
+		from, to = m.now, startOfDay(m.now).AddDate(0, 0, agendaDays)
+	}
+	return m.occurrences(from, to)
+}
+
+// selected returns the occurrence the cursor is on
+func (m Model) selected() (occurrence, bool) {
+	var occs []occurrence
+	if m.mode == viewAgenda {
+		occs = m.agenda()
+	} else {
+		occs = m.slotOccurrences()
+	}
+	if m.entry < 0 || m.entry >= len(occs) {
+		return occurrence{}, false
+	}
+	return occs[m.entry], true
+}
+
+// Rendering
+
+func (m Model) View() string {
+	if m.width == 0 || m.height == 0 {
+		return ""
+	}
+
+	t := theme.CurrentTheme()
+	if t == nil {
+		return "No theme loaded"
+	}
+
+	var s strings.Builder
+
+	// Header
+	headerStyle := styles.NewStyle().
+		Foreground(t.Primary()).
+		Bold(true)
+
+	s.WriteString(headerStyle.Render("> CALENDAR"))
+	s.WriteString("\n\n")
+
+	s.WriteString(m.renderModeTabs())
+	s.WriteString("  ")
+	s.WriteString(styles.NewStyle().Foreground(t.Text()).Bold(true).Render(m.periodTitle()))
+	s.WriteString("\n\n")
+
+	if m.err != nil {
+		errorStyle := styles.NewStyle().
+			Foreground(t.Error()).
+			Width(m.width-4).
+			Height(m.height-10).
+			Align(lipgloss.Center, lipgloss.Center)
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Error loading calendar:\n%v\n\nPress [R] to retry", m.err)))
+		return s.String()
+	}
+
+	switch m.mode {
+	case viewDay:
+		s.WriteString(m.renderDay())
+	case viewWeek:
+		s.WriteString(m.renderWeek())
+	case viewMonth:
+		s.WriteString(m.renderMonth())
+	case viewAgenda:
+		s.WriteString(m.renderAgenda())
+	}
+
+	if m.mode != viewAgenda {
+		s.WriteString("\n\n")
+		s.WriteString(m.renderSlotDetails())
+	}
+
+	if len(m.invalid) > 0 {
+		warnStyle := styles.NewStyle().Foreground(t.Warning())
+		s.WriteString("\n\n")
+		s.WriteString(warnStyle.Render("Unparseable schedules: " + strings.Join(m.invalid, ", ")))
+	}
+
+	// Help text
+	helpStyle := styles.NewStyle().
+		Foreground(t.TextMuted())
+
+	contentHeight := lipgloss.Height(s.String())
+	if remaining := m.height - contentHeight - 1; remaining > 0 {
+		s.WriteString(strings.Repeat("\n", remaining))
+	}
+	s.WriteString("\n")
+	s.WriteString(helpStyle.Render("v view • ←/→ day • ↑/↓ move • [/] period • t today • n next in slot • enter open task • r reload"))
+
+	return s.String()
+}
+
+func (m Model) renderModeTabs() string {
+	t := theme.CurrentTheme()
+
+	var tabs []string
+	for i, name := range viewNames {
+		style := styles.NewStyle().
+			Padding(0, 1)
+
+		if i == int(m.mode) {
+			style = style.
+				Background(t.Primary()).
+				Foreground(t.Background()).
+				Bold(true)
+		} else {
+			style = style.
+				Background(t.BackgroundElement()).
+				Foreground(t.Text())
+		}
+
+		tabs = append(tabs, style.Render(name))
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, tabs...)
+}
+
+func (m Model) periodTitle() string {
+	start, end := m.period()
+	last := end.Add(-time.Nanosecond)
+
+	switch m.mode {
+	case viewDay:
+		return start.Format("Monday, Jan 2 2006")
+	case viewMonth:
+		return start.Format("January 2006")
+	default:
+		return fmt.Sprintf("%s – %s", start.Format("Mon Jan 2"), last.Format("Mon Jan 2, 2006"))
+	}
+}
+
+// glyph returns the plain symbol for an occurrence
+func glyph(occ occurrence) string {
+	switch occ.status {
+	case "":
+		return "○"
+	case "SUCCESS":
+		return "✓"
+	case "FAILURE", "ERROR":
+		return "✗"
+	default:
+		return "–"
+	}
+}
+
+func statusColor(occ occurrence) compat.AdaptiveColor {
+	t := theme.CurrentTheme()
+
+	switch occ.status {
+	case "":
+		return t.Secondary()
+	case "SUCCESS":
+		return t.Success()
+	case "FAILURE", "ERROR":
+		return t.Error()
+	default:
+		return t.TextMuted()
+	}
+}
+
+// mark renders an occurrence's glyph in its status color
+func mark(occ occurrence) string {
+	return styles.NewStyle().Foreground(statusColor(occ)).Render(glyph(occ))
+}
+
+// notable picks the occurrence that best represents a group: failures win
+// over successes, which win over projections
+func notable(occs []occurrence) occurrence {
+	best := occs[0]
+	for _, occ := range occs {
+		switch {
+		case occ.status == "FAILURE" || occ.status == "ERROR":
+			return occ
+		case occ.status == "SUCCESS":
+			best = occ
+		}
+	}
+	return best
+}
+
+// gridRows is how many rows the hour grids can show
+func (m Model) gridRows() int {
+	rows := m.height - 18
+	if rows < 4 {
+		rows = 4
+	}
+	if rows > 24 {
+		rows = 24
+	}
+	return rows
+}
+
+// visibleHours returns the window of hours to draw, keeping the cursor visible
+func (m Model) visibleHours() (int, int) {
+	rows := m.gridRows()
+	first := m.cursor.Hour() - rows/2
+	if first < 0 {
+		first = 0
+	}
+	if first+rows > 24 {
+		first = 24 - rows
+	}
+	return first, first + rows
+}
+
+func (m Model) renderDay() string {
+	t := theme.CurrentTheme()
+	day, _ := m.period()
+	width := m.width - 4
+
+	var s strings.Builder
+	first, last := m.visibleHours()
+
+	for h := first; h < last; h++ {
+		slot := day.Add(time.Duration(h) * time.Hour)
+		occs := m.occurrences(slot, slot.Add(time.Hour))
+
+		var cells []string
+		for _, occ := range occs {
+			cells = append(cells, fmt.Sprintf("%s %s %s", mark(occ), occ.at.Format("15:04"), occ.taskName))
+		}
+
+		labelStyle := styles.NewStyle().Foreground(t.TextMuted())
+		if slot.Equal(m.cursor) {
+			labelStyle = labelStyle.Foreground(t.Primary()).Bold(true)
+		}
+		if startOfHour(m.now).Equal(slot) {
+			labelStyle = labelStyle.Underline(true)
+		}
+
+		line := labelStyle.Render(fmt.Sprintf("%02d:00", h)) + " │ " + strings.Join(cells, "  ")
+		s.WriteString(lipgloss.NewStyle().MaxWidth(width).Render(line))
+		if h < last-1 {
+			s.WriteString("\n")
+		}
+	}
+
+	return s.String()
+}
+
+func (m Model) renderWeek() string {
+	t := theme.CurrentTheme()
+	start, _ := m.period()
+
+	colWidth := (m.width - 4 - 8) / 7
+	if colWidth < 6 {
+		colWidth = 6
+	}
+
+	var s strings.Builder
+
+	// Column headers
+	s.WriteString(strings.Repeat(" ", 8))
+	for d := 0; d < 7; d++ {
+		day := start.AddDate(0, 0, d)
+		style := styles.NewStyle().Width(colWidth).Foreground(t.TextMuted())
+		if startOfDay(m.now).Equal(day) {
+			style = style.Foreground(t.Primary()).Bold(true)
+		}
+		s.WriteString(style.Render(day.Format("Mon 2")))
+	}
+	s.WriteString("\n")
+
+	first, last := m.visibleHours()
+	for h := first; h < last; h++ {
+		s.WriteString(styles.NewStyle().Foreground(t.TextMuted()).Render(fmt.Sprintf("%02d:00 │ ", h)))
+
+		for d := 0; d < 7; d++ {
+			slot := start.AddDate(0, 0, d).Add(time.Duration(h) * time.Hour)
+			occs := m.occurrences(slot, slot.Add(time.Hour))
+
+			cell := "·"
+			style := styles.NewStyle().Width(colWidth).MaxWidth(colWidth).Foreground(t.BorderSubtle())
+			if len(occs) > 0 {
+				occ := notable(occs)
+				cell = glyph(occ) + " " + occs[0].taskName
+				if len(occs) > 1 {
+					cell = fmt.Sprintf("%s ×%d", glyph(occ), len(occs))
+				}
+				style = style.Foreground(statusColor(occ))
+			}
+			if slot.Equal(m.cursor) {
+				style = style.Background(t.BackgroundElement()).Bold(true)
+			}
+			s.WriteString(style.Render(cell))
+		}
+
+		if h < last-1 {
+			s.WriteString("\n")
+		}
+	}
+
+	return s.String()
+}
+
+func (m Model) renderMonth() string {
+	t := theme.CurrentTheme()
+	monthStart, monthEnd := m.period()
+	gridStart := startOfWeek(monthStart)
+
+	colWidth := (m.width - 4) / 7
+	if colWidth < 6 {
+		colWidth = 6
+	}
+
+	var s strings.Builder
+
+	for _, name := range []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"} {
+		s.WriteString(styles.NewStyle().Width(colWidth).Foreground(t.TextMuted()).Render(name))
+	}
+	s.WriteString("\n")
+
+	for week := 0; week < 6; week++ {
+		weekStart := gridStart.AddDate(0, 0, 7*week)
+		if !weekStart.Before(monthEnd) {
+			break
+		}
+		if week > 0 {
+			s.WriteString("\n")
+		}
+
+		for d := 0; d < 7; d++ {
+			day := weekStart.AddDate(0, 0, d)
+			occs := m.occurrences(day, day.AddDate(0, 0, 1))
+
+			number := fmt.Sprintf("%2d", day.Day())
+			count := ""
+			if len(occs) > 0 {
+				count = fmt.Sprintf(" %s%d", glyph(notable(occs)), len(occs))
+			}
+
+			if day.Equal(m.cursor) {
+				s.WriteString(styles.NewStyle().
+					Width(colWidth).
+					Background(t.BackgroundElement()).
+					Foreground(t.Primary()).
+					Bold(true).
+					Render(number + count))
+				continue
+			}
+
+			numberStyle := styles.NewStyle().Foreground(t.Text())
+			if day.Before(monthStart) || !day.Before(monthEnd) {
+				numberStyle = numberStyle.Foreground(t.BorderSubtle())
+			}
+			if startOfDay(m.now).Equal(day) {
+				numberStyle = numberStyle.Foreground(t.Primary()).Bold(true)
+			}
+
+			cell := numberStyle.Render(number)
+			if len(occs) > 0 {
+				cell += styles.NewStyle().Foreground(statusColor(notable(occs))).Render(count)
+			}
+			s.WriteString(lipgloss.NewStyle().Width(colWidth).Render(cell))
+		}
+	}
+
+	return s.String()
+}
+
+func (m Model) renderAgenda() string {
+	t := theme.CurrentTheme()
+	occs := m.agenda()
+	width := m.width - 4
+
+	if len(occs) == 0 {
+		return styles.NewStyle().
+			Foreground(t.TextMuted()).
+			Render(fmt.Sprintf("No runs scheduled in the next %d days", agendaDays))
+	}
+
+	// Keep the selected entry on screen
+	rows := m.gridRows()
+	first := m.entry - rows/2
+	if first < 0 {
+		first = 0
+	}
+
+	var s strings.Builder
+	var lastDay time.Time
+	lines := 0
+
+	for i := first; i < len(occs) && lines < rows; i++ {
+		occ := occs[i]
+
+		if day := startOfDay(occ.at); !day.Equal(lastDay) {
+			lastDay = day
+			if lines > 0 {
+				s.WriteString("\n")
+			}
+			s.WriteString(styles.NewStyle().Foreground(t.Text()).Bold(true).Render(day.Format("Monday, Jan 2")))
+			s.WriteString("\n")
+			lines++
+		}
+
+		style := styles.NewStyle().Foreground(t.Text())
+		prefix := "  "
+		if i == m.entry {
+			style = style.Foreground(t.Primary()).Bold(true)
+			prefix = "✦ "
+		}
+
+		line := style.Render(prefix+occ.at.Format("15:04")+" ") + mark(occ) + style.Render("  "+occ.taskName)
+		if sched, ok := m.schedules[occ.taskID]; ok {
+			line += styles.NewStyle().Foreground(t.TextMuted()).Render("  " + sched.Description)
+		}
+		s.WriteString(lipgloss.NewStyle().MaxWidth(width).Render(line))
+		s.WriteString("\n")
+		lines++
+	}
+
+	return strings.TrimSuffix(s.String(), "\n")
+}
+
+func (m Model) renderSlotDetails() string {
+	t := theme.CurrentTheme()
+	occs := m.slotOccurrences()
+
+	var s strings.Builder
+
+	title := m.cursor.Format("Mon Jan 2, 15:04")
+	if m.mode == viewMonth {
+		title = m.cursor.Format("Monday, Jan 2")
+	}
+	s.WriteString(styles.NewStyle().Foreground(t.Text()).Bold(true).Render(title))
+	s.WriteString("\n")
+
+	if len(occs) == 0 {
+		s.WriteString(styles.NewStyle().Foreground(t.TextMuted()).Render("  Nothing scheduled"))
+		return s.String()
+	}
+
+	const maxDetails = 5
+	for i, occ := range occs {
+		if i >= maxDetails {
+			s.WriteString(styles.NewStyle().Foreground(t.TextMuted()).Render(fmt.Sprintf("  … %d more", len(occs)-maxDetails)))
+			break
+		}
+
+		style := styles.NewStyle().Foreground(t.Text())
+		prefix := "  "
+		if i == m.entry {
+			style = style.Foreground(t.Primary()).Bold(true)
+			prefix = "✦ "
+		}
+		s.WriteString(style.Render(fmt.Sprintf("%s%s ", prefix, occ.at.Format("15:04"))))
+		s.WriteString(mark(occ))
+		s.WriteString(style.Render(" " + occ.taskName))
+		if i < len(occs)-1 {
+			s.WriteString("\n")
+		}
+	}
+
+	return s.String()
+}
+
+// Commands
+
+type dataLoadedMsg struct {
+	tasks []api.Task
+	logs  []api.LogEntry
+}
+
+type errorMsg struct {
+	err error
+}
+
+// startLoad fetches tasks and logs, cancelling any load still in flight so
+// an older response can't overwrite a newer one
+func (m *Model) startLoad() tea.Cmd {
+	if m.cancelLoad != nil {
+		m.cancelLoad()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelLoad = cancel
+
+	client := m.client
+	return func() tea.Msg {
+		tasks, err := client.GetTasksContext(ctx)
+		if err != nil {
+			return errorMsg{err: err}
+		}
+
+		// Past executions are an overlay; the calendar still works without them
+		logs, err := client.GetLogsContext(ctx)
+		if err != nil {
+			logs = nil
+		}
+		if ctx.Err() != nil {
+			return errorMsg{err: ctx.Err()}
+		}
+
+		return dataLoadedMsg{tasks: tasks, logs: logs}
+	}
+}
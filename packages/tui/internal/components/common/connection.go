@@ -0,0 +1,51 @@
+// ABOUTME: Connection state messages and indicator for the API client
+// ABOUTME: Turns the client's state channel into Bubbletea messages
+
+package common
+
+import (
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/styles"
+	"github.com/jem-computer/ritual/tui/internal/theme"
+)
+
+// ConnectionMsg reports a change in the client's connection to the server.
+// Components use it to hold their last data while reconnecting and to
+// reload once the server is back.
+type ConnectionMsg struct {
+	State api.ConnState
+}
+
+// WaitForConnection returns a command that delivers the client's next
+// connection state change. Re-issue it after each ConnectionMsg.
+func WaitForConnection(client *api.Client) tea.Cmd {
+	return func() tea.Msg {
+		return ConnectionMsg{State: <-client.ConnStates()}
+	}
+}
+
+// ConnectionIndicator renders a short label for the tab bar in states other
+// than connected, and nothing when all is well
+func ConnectionIndicator(state api.ConnState) string {
+	t := theme.CurrentTheme()
+	if t == nil {
+		return ""
+	}
+
+	switch state {
+	case api.Reconnecting:
+		return styles.NewStyle().Foreground(t.Warning()).Background(t.BackgroundPanel()).Render("⟳ reconnecting…")
+	case api.Unauthorized:
+		return styles.NewStyle().Foreground(t.Error()).Background(t.BackgroundPanel()).Render("🔒 unauthorized · ctrl+l to log in")
+	case api.Disconnected:
+		return styles.NewStyle().Foreground(t.Error()).Background(t.BackgroundPanel()).Render("⟳ server unreachable, retrying…")
+	}
+	return ""
+}
+
+// Transient reports whether err is the server being away rather than a
+// real failure, so a component should wait for it to come back
+func Transient(err error, state api.ConnState) bool {
+	return err != nil && state != api.Connected && api.StatusCode(err) == 0
+}
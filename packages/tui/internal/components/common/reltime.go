@@ -0,0 +1,67 @@
+// ABOUTME: Relative time formatting helpers for countdowns and "time ago" labels
+// ABOUTME: Used by components that render live-updating run times
+
+package common
+
+import (
+	"fmt"
+	"time"
+)
+
+// Now is the clock components read the current time from. Tests replace it
+// so rendered times are the same on every run.
+var Now = time.Now
+
+// Countdown formats a positive duration as a compact two-unit countdown,
+// e.g. "42s", "4m 12s", "3h 5m" or "2d 4h".
+func Countdown(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	d = d.Truncate(time.Second)
+
+	days := int(d / (24 * time.Hour))
+	hours := int(d/time.Hour) % 24
+	minutes := int(d/time.Minute) % 60
+	seconds := int(d/time.Second) % 60
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm %ds", minutes, seconds)
+	default:
+		return fmt.Sprintf("%ds", seconds)
+	}
+}
+
+// Ago formats a past duration using its largest unit, e.g. "12s ago" or "3h ago".
+func Ago(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+
+	switch {
+	case d < 5*time.Second:
+		return "just now"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds ago", int(d/time.Second))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dd ago", int(d/(24*time.Hour)))
+	}
+}
+
+// RelativeTime formats t relative to now: "in 4m 12s" for future times and
+// "3h ago" for past ones.
+func RelativeTime(t, now time.Time) string {
+	if t.After(now) {
+		return "in " + Countdown(t.Sub(now))
+	}
+	return Ago(now.Sub(t))
+}
@@ -0,0 +1,47 @@
+// ABOUTME: Sparkline component for rendering a compact run history strip
+// ABOUTME: Colors one cell per execution by outcome (success, failure, skipped)
+
+package common
+
+import (
+	"strings"
+
+	"github.com/jem-computer/ritual/tui/internal/styles"
+	"github.com/jem-computer/ritual/tui/internal/theme"
+)
+
+// RunSparkline renders one cell per execution status, oldest first.
+// SUCCESS is green, FAILURE/ERROR is red and anything else counts as skipped
+// and is drawn grey. Missing slots up to width are padded with a faint dot.
+func RunSparkline(statuses []string, width int) string {
+	t := theme.CurrentTheme()
+	if t == nil {
+		return ""
+	}
+
+	if len(statuses) > width {
+		statuses = statuses[len(statuses)-width:]
+	}
+
+	var s strings.Builder
+
+	pad := styles.NewStyle().Foreground(t.BorderSubtle())
+	for i := len(statuses); i < width; i++ {
+		s.WriteString(pad.Render("·"))
+	}
+
+	for _, status := range statuses {
+		style := styles.NewStyle()
+		switch status {
+		case "SUCCESS":
+			style = style.Foreground(t.Success())
+		case "FAILURE", "ERROR":
+			style = style.Foreground(t.Error())
+		default:
+			style = style.Foreground(t.TextMuted())
+		}
+		s.WriteString(style.Render("▮"))
+	}
+
+	return s.String()
+}
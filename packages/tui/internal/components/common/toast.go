@@ -0,0 +1,90 @@
+// ABOUTME: Toast component for short-lived notifications
+// ABOUTME: Shows a message in a small bordered box that dismisses itself after a few seconds
+
+package common
+
+import (
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/jem-computer/ritual/tui/internal/styles"
+	"github.com/jem-computer/ritual/tui/internal/theme"
+)
+
+// ToastDuration is how long a toast stays up
+const ToastDuration = 4 * time.Second
+
+// toastSeq numbers toasts across all components, since every component
+// sees every ToastExpiredMsg
+var toastSeq atomic.Int64
+
+// Toast is a notification that disappears on its own. The zero value is
+// hidden.
+type Toast struct {
+	id   int64
+	text string
+	kind BadgeType
+}
+
+// ToastExpiredMsg hides the toast it was issued for
+type ToastExpiredMsg struct {
+	id int64
+}
+
+// Show displays text, replacing any current toast, and returns the command
+// that hides it again. kind picks the color, e.g. BadgeError.
+func (t *Toast) Show(text string, kind BadgeType) tea.Cmd {
+	t.id = toastSeq.Add(1)
+	t.text = text
+	t.kind = kind
+
+	id := t.id
+	return tea.Tick(ToastDuration, func(time.Time) tea.Msg {
+		return ToastExpiredMsg{id: id}
+	})
+}
+
+// Update hides the toast once its time is up; a newer toast stays
+func (t Toast) Update(msg ToastExpiredMsg) Toast {
+	if msg.id == t.id {
+		t.text = ""
+	}
+	return t
+}
+
+// Visible reports whether the toast is showing
+func (t Toast) Visible() bool {
+	return t.text != ""
+}
+
+// View renders the toast no wider than width, or "" when it's hidden
+func (t Toast) View(width int) string {
+	th := theme.CurrentTheme()
+	if th == nil || !t.Visible() {
+		return ""
+	}
+
+	color := th.Info()
+	switch t.kind {
+	case BadgeError:
+		color = th.Error()
+	case BadgeWarning, BadgePaused:
+		color = th.Warning()
+	case BadgeSuccess, BadgeActive:
+		color = th.Success()
+	}
+
+	// Wrap long messages inside the border and padding
+	text := styles.NewStyle().
+		Foreground(color).
+		Width(max(min(lipgloss.Width(t.text), width-4), 1)).
+		Render(t.text)
+
+	return styles.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(color).
+		Padding(0, 1).
+		Render(text)
+}
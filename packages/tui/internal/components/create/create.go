@@ -6,7 +6,7 @@ package create
 import (
 	"fmt"
 	"strings"
-	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/v2/key"
 	"github.com/charmbracelet/bubbles/v2/textarea"
@@ -14,7 +14,11 @@ import (
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
 	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/components/common"
+	"github.com/jem-computer/ritual/tui/internal/prompt"
+	"github.com/jem-computer/ritual/tui/internal/schedule"
 	"github.com/jem-computer/ritual/tui/internal/styles"
+	"github.com/jem-computer/ritual/tui/internal/templates"
 	"github.com/jem-computer/ritual/tui/internal/theme"
 )
 
@@ -22,6 +26,7 @@ type state int
 
 const (
 	stateForm state = iota
+	stateGallery
 	stateSubmitting
 	stateSuccess
 	stateError
@@ -33,10 +38,27 @@ const (
 	fieldName field = iota
 	fieldPrompt
 	fieldSchedule
+	fieldScheduleDay
+	fieldScheduleTime
+	fieldScheduleCustom
 	fieldModel
 	fieldOutput
+	fieldPreview
+	// fieldVariable is the first of one focus slot per template variable,
+	// so it must stay last
+	fieldVariable
 )
 
+// text reports whether the field is typed into rather than picked from
+func (f field) text() bool {
+	switch f {
+	case fieldName, fieldPrompt, fieldScheduleTime, fieldScheduleCustom:
+		return true
+	}
+	_, ok := variableIndex(f)
+	return ok
+}
+
 type Model struct {
 	client *api.Client
 	state  state
@@ -50,22 +72,57 @@ type Model struct {
 	promptInput   textarea.Model
 	scheduleInput textinput.Model
 	// modelIndex    int
+
+	// Schedule picker: a preset plus its sub-inputs, or free text in
+	// scheduleInput for the custom preset
+	schedulePreset preset
+	scheduleDay    int
+	timeInput      textinput.Model
+
+	// Live interpretation of scheduleInput, updated as the user types
+	parsedSchedule *schedule.Schedule
+	scheduleErr    error
 	// outputIndex   int
 
 	// Current focused field
 	focusedField field
 
+	// Template variables detected in the prompt, with an input for each,
+	// and the upcoming run the expanded-prompt preview is rendered for
+	varNames    []string
+	varInputs   []textinput.Model
+	previewRun  int
+	templateErr error
+
+	// Model, output and status aren't editable in the form yet, but
+	// templates and clones carry them through to the created task.
+	// prefilledFrom describes where the values came from.
+	modelValue    string
+	outputValue   string
+	statusValue   string
+	prefilledFrom string
+
+	// Template gallery
+	gallery       []templates.Template
+	galleryErrs   []error
+	galleryCursor int
+
+	// Set when the external editor failed to launch or its file couldn't be read
+	editorErr error
+
 	// Options
 	// modelOptions  []string
 	// outputOptions []string
 }
 
 type keyMap struct {
-	Up     key.Binding
-	Down   key.Binding
-	Tab    key.Binding
-	Submit key.Binding
-	Back   key.Binding
+	Up        key.Binding
+	Down      key.Binding
+	Tab       key.Binding
+	Submit    key.Binding
+	Back      key.Binding
+	Editor    key.Binding
+	Templates key.Binding
 }
 
 func defaultKeyMap() keyMap {
@@ -90,6 +147,14 @@ func defaultKeyMap() keyMap {
 			key.WithKeys("esc"),
 			key.WithHelp("esc", "back"),
 		),
+		Editor: key.NewBinding(
+			key.WithKeys("ctrl+e"),
+			key.WithHelp("ctrl+e", "edit prompt in $EDITOR"),
+		),
+		Templates: key.NewBinding(
+			key.WithKeys("ctrl+t"),
+			key.WithHelp("ctrl+t", "browse templates"),
+		),
 	}
 }
 
@@ -103,23 +168,33 @@ func New(client *api.Client) Model {
 	// Initialize textarea
 	promptInput := textarea.New()
 	promptInput.Placeholder = "Summarize my calendar events for today and format as a brief standup update..."
-	promptInput.CharLimit = 1000
+	promptInput.CharLimit = 0 // Long prompts are expected; compose them in $EDITOR
+	promptInput.MaxHeight = 1000
 	promptInput.SetWidth(50)
 	promptInput.SetHeight(4)
 
 	// Initialize schedule input
 	scheduleInput := textinput.New()
-	scheduleInput.Placeholder = "Daily at 9am"
+	scheduleInput.Placeholder = "daily at 9:00 am"
 	scheduleInput.CharLimit = 100
 
-	return Model{
-		client:        client,
-		state:         stateForm,
-		keys:          defaultKeyMap(),
-		nameInput:     nameInput,
-		promptInput:   promptInput,
-		scheduleInput: scheduleInput,
-		focusedField:  fieldName,
+	// Initialize preset time input
+	timeInput := textinput.New()
+	timeInput.Placeholder = "09:00"
+	timeInput.CharLimit = 5
+	timeInput.SetValue("09:00")
+
+	m := Model{
+		client:         client,
+		state:          stateForm,
+		keys:           defaultKeyMap(),
+		nameInput:      nameInput,
+		promptInput:    promptInput,
+		scheduleInput:  scheduleInput,
+		schedulePreset: presetDaily,
+		statusValue:    "ACTIVE",
+		timeInput:      timeInput,
+		focusedField:   fieldName,
 		// modelIndex:    0,
 		// outputIndex: 0,
 		// modelOptions: []string{
@@ -136,6 +211,9 @@ func New(client *api.Client) Model {
 		// 	"Webhook",
 		// },
 	}
+	m.parseSchedule()
+	m.syncVariables()
+	return m
 }
 
 func (m Model) Init() (tea.Model, tea.Cmd) {
@@ -152,6 +230,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tea.KeyMsg:
 		switch m.state {
+		case stateGallery:
+			return m.updateGallery(msg)
+
 		case stateForm:
 			switch {
 			case key.Matches(msg, m.keys.Back):
@@ -164,6 +245,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, m.createTask()
 				}
 
+			case key.Matches(msg, m.keys.Editor):
+				m.editorErr = nil
+				return m, m.openEditor()
+
+			case key.Matches(msg, m.keys.Templates):
+				m.state = stateGallery
+				m.galleryCursor = 0
+				return m, loadTemplates
+
 			case key.Matches(msg, m.keys.Up):
 				m.focusPrevField()
 
@@ -182,11 +272,57 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					var cmd tea.Cmd
 					m.promptInput, cmd = m.promptInput.Update(msg)
 					cmds = append(cmds, cmd)
+					m.syncVariables()
 
 				case fieldSchedule:
+					switch msg.String() {
+					case "left":
+						m.setPreset((m.schedulePreset + preset(len(presetNames)) - 1) % preset(len(presetNames)))
+					case "right":
+						m.setPreset((m.schedulePreset + 1) % preset(len(presetNames)))
+					}
+
+				case fieldScheduleDay:
+					lo, hi := m.schedulePreset.dayRange()
+					switch msg.String() {
+					case "left":
+						if m.scheduleDay--; m.scheduleDay < lo {
+							m.scheduleDay = hi
+						}
+					case "right":
+						if m.scheduleDay++; m.scheduleDay > hi {
+							m.scheduleDay = lo
+						}
+					}
+					m.parseSchedule()
+
+				case fieldScheduleTime:
+					var cmd tea.Cmd
+					m.timeInput, cmd = m.timeInput.Update(msg)
+					cmds = append(cmds, cmd)
+					m.parseSchedule()
+
+				case fieldScheduleCustom:
 					var cmd tea.Cmd
 					m.scheduleInput, cmd = m.scheduleInput.Update(msg)
 					cmds = append(cmds, cmd)
+					m.parseSchedule()
+
+				case fieldPreview:
+					runs := len(m.previewTimes())
+					switch msg.String() {
+					case "left":
+						m.previewRun = (m.previewRun + runs - 1) % runs
+					case "right":
+						m.previewRun = (m.previewRun + 1) % runs
+					}
+
+				default:
+					if i, ok := variableIndex(m.focusedField); ok {
+						var cmd tea.Cmd
+						m.varInputs[i], cmd = m.varInputs[i].Update(msg)
+						cmds = append(cmds, cmd)
+					}
 
 					// case fieldModel:
 					// 	switch msg.String() {
@@ -228,6 +364,25 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case tea.PasteMsg:
+		if m.state == stateForm {
+			cmds = append(cmds, m.paste(msg))
+		}
+
+	case editorFinishedMsg:
+		m.editorErr = msg.err
+		if msg.err == nil {
+			m.promptInput.SetValue(msg.content)
+			m.focusedField = fieldPrompt
+			m.syncVariables()
+			m.updateFocus()
+		}
+
+	case templatesLoadedMsg:
+		m.gallery = msg.templates
+		m.galleryErrs = msg.errs
+		m.galleryCursor = min(m.galleryCursor, max(len(m.gallery)-1, 0))
+
 	case taskCreatedMsg:
 		m.state = stateSuccess
 
@@ -256,7 +411,11 @@ func (m Model) View() string {
 		Foreground(t.Primary()).
 		Bold(true)
 
-	s.WriteString(headerStyle.Render("> CREATE NEW TASK"))
+	if m.state == stateGallery {
+		s.WriteString(headerStyle.Render("> CHOOSE A TEMPLATE"))
+	} else {
+		s.WriteString(headerStyle.Render("> CREATE NEW TASK"))
+	}
 	s.WriteString("\n\n")
 
 	// Content based on state
@@ -264,6 +423,9 @@ func (m Model) View() string {
 	case stateForm:
 		s.WriteString(m.renderForm())
 
+	case stateGallery:
+		s.WriteString(m.renderGallery())
+
 	case stateSubmitting:
 		loadingStyle := styles.NewStyle().
 			Foreground(t.TextMuted()).
@@ -297,16 +459,48 @@ func (m Model) renderForm() string {
 
 	var s strings.Builder
 
+	if m.prefilledFrom != "" {
+		s.WriteString(m.renderPrefillSummary())
+		s.WriteString("\n\n")
+	}
+
 	// Name field
 	s.WriteString(m.renderField("Task Name", m.nameInput.View(), m.focusedField == fieldName))
 	s.WriteString("\n\n")
 
 	// Prompt field
 	s.WriteString(m.renderField("Prompt", m.promptInput.View(), m.focusedField == fieldPrompt))
+	s.WriteString("\n")
+	s.WriteString(m.renderPromptStats())
 	s.WriteString("\n\n")
 
+	// Template variables and expanded preview, only for templated prompts
+	if prompt.HasDirectives(m.promptInput.Value()) || m.templateErr != nil {
+		if len(m.varNames) > 0 {
+			s.WriteString(m.renderField("Variables", m.renderVariables(), false))
+			s.WriteString("\n")
+		}
+		s.WriteString(m.renderField("Expanded Prompt", m.renderPromptPreview(), m.focusedField == fieldPreview))
+		s.WriteString("\n\n")
+	}
+
 	// Schedule field
-	s.WriteString(m.renderField("Schedule", m.scheduleInput.View(), m.focusedField == fieldSchedule))
+	presetView := m.renderSelect(presetNames[m.schedulePreset], m.focusedField == fieldSchedule)
+	s.WriteString(m.renderField("Schedule", presetView, m.focusedField == fieldSchedule))
+	s.WriteString("\n")
+	if m.schedulePreset.usesDay() {
+		s.WriteString(m.renderSubField("On", "◀ "+m.schedulePreset.dayLabel(m.scheduleDay)+" ▶", m.focusedField == fieldScheduleDay))
+		s.WriteString("\n")
+	}
+	if m.schedulePreset.usesTime() {
+		s.WriteString(m.renderSubField("At", m.timeInput.View(), m.focusedField == fieldScheduleTime))
+		s.WriteString("\n")
+	}
+	if m.schedulePreset == presetCustom {
+		s.WriteString(m.renderSubField("Expr", m.scheduleInput.View(), m.focusedField == fieldScheduleCustom))
+		s.WriteString("\n")
+	}
+	s.WriteString(m.renderSchedulePreview())
 	s.WriteString("\n\n")
 
 	// Model field
@@ -323,11 +517,32 @@ func (m Model) renderForm() string {
 		Foreground(t.TextMuted()).
 		MarginTop(2)
 	s.WriteString("\n\n")
-	s.WriteString(helpStyle.Render("Use ↑/↓ to navigate • ←/→ to change options • Ctrl+S to submit"))
+	s.WriteString(helpStyle.Render("Use ↑/↓ to navigate • ←/→ to change options • Ctrl+T for templates • Ctrl+S to submit"))
 
 	return s.String()
 }
 
+// renderPrefillSummary notes where the form was pre-filled from and the
+// model, output and status it carries, since those aren't form fields yet
+func (m Model) renderPrefillSummary() string {
+	t := theme.CurrentTheme()
+
+	parts := []string{m.prefilledFrom}
+	if m.modelValue != "" {
+		parts = append(parts, "Model: "+m.modelValue)
+	}
+	if m.outputValue != "" {
+		parts = append(parts, "Output: "+m.outputValue)
+	}
+	if m.statusValue == "PAUSED" {
+		parts = append(parts, "Starts paused")
+	}
+
+	return styles.NewStyle().
+		Foreground(t.Info()).
+		Render(strings.Join(parts, " · "))
+}
+
 func (m Model) renderField(label, value string, focused bool) string {
 	t := theme.CurrentTheme()
 
@@ -342,6 +557,74 @@ func (m Model) renderField(label, value string, focused bool) string {
 	return labelStyle.Render(label) + "\n" + value
 }
 
+// renderPromptStats shows the prompt's size and token estimate, or the
+// editor error if composing in $EDITOR failed
+func (m Model) renderPromptStats() string {
+	t := theme.CurrentTheme()
+
+	if m.editorErr != nil {
+		return styles.NewStyle().
+			Foreground(t.Error()).
+			PaddingLeft(2).
+			Render(fmt.Sprintf("✗ %s: %v", editorName(), m.editorErr))
+	}
+
+	prompt := m.promptInput.Value()
+	return styles.NewStyle().
+		Foreground(t.TextMuted()).
+		PaddingLeft(2).
+		Render(fmt.Sprintf("%d chars · %d lines · ~%d tokens • Ctrl+E to edit in %s",
+			utf8.RuneCountInString(prompt), m.promptInput.LineCount(), estimateTokens(prompt), editorName()))
+}
+
+// previewRuns is the number of upcoming fire times listed under the schedule
+const previewRuns = 5
+
+// renderSchedulePreview shows how the schedule field is interpreted: a
+// description, the next fire times in local time and the equivalent cron
+func (m Model) renderSchedulePreview() string {
+	t := theme.CurrentTheme()
+
+	mutedStyle := styles.NewStyle().
+		Foreground(t.TextMuted()).
+		PaddingLeft(2)
+
+	if m.scheduleErr != nil {
+		return styles.NewStyle().
+			Foreground(t.Error()).
+			PaddingLeft(2).
+			Render("✗ " + m.scheduleErr.Error())
+	}
+	if m.parsedSchedule == nil {
+		return mutedStyle.Render(`e.g. "daily at 9:00 am", "every monday at 8:30 am", "every 2 hours" or "0 9 * * 1-5"`)
+	}
+
+	var s strings.Builder
+
+	s.WriteString(styles.NewStyle().
+		Foreground(t.Success()).
+		PaddingLeft(2).
+		Render("✓ " + m.parsedSchedule.Description))
+	s.WriteString("\n")
+
+	now := common.Now()
+	zone, _ := now.Zone()
+	runs := m.parsedSchedule.Upcoming(now, previewRuns)
+	if len(runs) == 0 {
+		s.WriteString(mutedStyle.Render("Never fires"))
+		s.WriteString("\n")
+	}
+	for _, run := range runs {
+		s.WriteString(mutedStyle.Render(fmt.Sprintf("%s %s  (%s)",
+			run.Format("Mon Jan 2, 15:04"), zone, common.RelativeTime(run, now))))
+		s.WriteString("\n")
+	}
+
+	s.WriteString(mutedStyle.Render("Cron: " + m.parsedSchedule.Cron))
+
+	return s.String()
+}
+
 func (m Model) renderSelect(value string, focused bool) string {
 	t := theme.CurrentTheme()
 
@@ -358,13 +641,86 @@ func (m Model) renderSelect(value string, focused bool) string {
 	return style.Render("◀ " + value + " ▶")
 }
 
+// renderSubField renders an indented inline label and value under a field
+func (m Model) renderSubField(label, value string, focused bool) string {
+	t := theme.CurrentTheme()
+
+	labelStyle := styles.NewStyle().
+		Foreground(t.TextMuted()).
+		PaddingLeft(2).
+		Width(8)
+
+	if focused {
+		labelStyle = labelStyle.Foreground(t.Primary()).Bold(true)
+	}
+
+	return labelStyle.Render(label) + value
+}
+
+// visibleFields lists the focusable fields in order; template variables
+// follow a templated prompt and the schedule sub-inputs depend on the
+// selected preset
+func (m Model) visibleFields() []field {
+	fields := []field{fieldName, fieldPrompt}
+	if prompt.HasDirectives(m.promptInput.Value()) {
+		for i := range m.varNames {
+			fields = append(fields, variableField(i))
+		}
+		fields = append(fields, fieldPreview)
+	}
+	fields = append(fields, fieldSchedule)
+	if m.schedulePreset.usesDay() {
+		fields = append(fields, fieldScheduleDay)
+	}
+	if m.schedulePreset.usesTime() {
+		fields = append(fields, fieldScheduleTime)
+	}
+	if m.schedulePreset == presetCustom {
+		fields = append(fields, fieldScheduleCustom)
+	}
+	return fields
+}
+
+// paste types pasted text into the focused field, if it's a text field
+func (m *Model) paste(msg tea.PasteMsg) tea.Cmd {
+	var cmd tea.Cmd
+	switch m.focusedField {
+	case fieldName:
+		m.nameInput, cmd = m.nameInput.Update(msg)
+	case fieldPrompt:
+		m.promptInput, cmd = m.promptInput.Update(msg)
+		m.syncVariables()
+	case fieldScheduleTime:
+		m.timeInput, cmd = m.timeInput.Update(msg)
+		m.parseSchedule()
+	case fieldScheduleCustom:
+		m.scheduleInput, cmd = m.scheduleInput.Update(msg)
+		m.parseSchedule()
+	default:
+		if i, ok := variableIndex(m.focusedField); ok {
+			m.varInputs[i], cmd = m.varInputs[i].Update(msg)
+		}
+	}
+	return cmd
+}
+
 func (m *Model) focusNextField() {
-	m.focusedField = (m.focusedField + 1) % 5
-	m.updateFocus()
+	m.moveFocus(1)
 }
 
 func (m *Model) focusPrevField() {
-	m.focusedField = (m.focusedField + 4) % 5
+	m.moveFocus(-1)
+}
+
+func (m *Model) moveFocus(delta int) {
+	fields := m.visibleFields()
+	current := 0
+	for i, f := range fields {
+		if f == m.focusedField {
+			current = i
+		}
+	}
+	m.focusedField = fields[(current+delta+len(fields))%len(fields)]
 	m.updateFocus()
 }
 
@@ -372,21 +728,77 @@ func (m *Model) updateFocus() {
 	m.nameInput.Blur()
 	m.promptInput.Blur()
 	m.scheduleInput.Blur()
+	m.timeInput.Blur()
+	for i := range m.varInputs {
+		m.varInputs[i].Blur()
+	}
+
+	if i, ok := variableIndex(m.focusedField); ok {
+		m.varInputs[i].Focus()
+		return
+	}
 
 	switch m.focusedField {
 	case fieldName:
 		m.nameInput.Focus()
 	case fieldPrompt:
 		m.promptInput.Focus()
-	case fieldSchedule:
+	case fieldScheduleTime:
+		m.timeInput.Focus()
+	case fieldScheduleCustom:
 		m.scheduleInput.Focus()
 	}
 }
 
+// setPreset switches the schedule preset, resetting its day selector to a
+// sensible default
+func (m *Model) setPreset(p preset) {
+	m.schedulePreset = p
+	switch p {
+	case presetWeekly:
+		m.scheduleDay = 1 // Monday
+	case presetMonthly:
+		m.scheduleDay = 1
+	default:
+		m.scheduleDay = 0
+	}
+	m.parseSchedule()
+}
+
+// scheduleValue returns the schedule string the form currently describes
+func (m Model) scheduleValue() (string, error) {
+	if m.schedulePreset == presetCustom {
+		return strings.TrimSpace(m.scheduleInput.Value()), nil
+	}
+	return m.schedulePreset.build(m.timeInput.Value(), m.scheduleDay)
+}
+
 func (m Model) validate() bool {
 	return strings.TrimSpace(m.nameInput.Value()) != "" &&
 		strings.TrimSpace(m.promptInput.Value()) != "" &&
-		strings.TrimSpace(m.scheduleInput.Value()) != ""
+		m.templateErr == nil &&
+		m.parsedSchedule != nil
+}
+
+// Editing reports whether the form has a focused text field, so the parent
+// shouldn't treat letter keys as shortcuts
+func (m Model) Editing() bool {
+	return m.state == stateForm && m.focusedField.text()
+}
+
+// parseSchedule refreshes the live interpretation of the schedule field
+func (m *Model) parseSchedule() {
+	m.parsedSchedule, m.scheduleErr = nil, nil
+
+	value, err := m.scheduleValue()
+	if err != nil {
+		m.scheduleErr = err
+		return
+	}
+	if value == "" {
+		return
+	}
+	m.parsedSchedule, m.scheduleErr = schedule.Parse(value)
 }
 
 func (m *Model) resetForm() {
@@ -394,7 +806,15 @@ func (m *Model) resetForm() {
 	m.err = nil
 	m.nameInput.SetValue("")
 	m.promptInput.SetValue("")
+	m.previewRun = 0
+	m.modelValue = ""
+	m.outputValue = ""
+	m.statusValue = "ACTIVE"
+	m.prefilledFrom = ""
+	m.syncVariables()
 	m.scheduleInput.SetValue("")
+	m.timeInput.SetValue("09:00")
+	m.setPreset(presetDaily)
 	// m.modelIndex = 0
 	// m.outputIndex = 0
 	m.focusedField = fieldName
@@ -418,11 +838,13 @@ func (m Model) createTask() tea.Cmd {
 		task := api.Task{
 			Name:     m.nameInput.Value(),
 			Prompt:   m.promptInput.Value(),
-			Schedule: m.scheduleInput.Value(),
+			Schedule: m.parsedSchedule.Expr,
 			// Model:    modelMap[m.modelIndex],
 			// Output:   outputMap[m.outputIndex],
-			Status:  "ACTIVE",
-			NextRun: calculateNextRun(m.scheduleInput.Value()),
+			Model:     m.modelValue,
+			Output:    m.outputValue,
+			Variables: m.variables(),
+			Status:    m.statusValue,
 		}
 
 		_, err := m.client.CreateTask(task)
@@ -433,33 +855,3 @@ func (m Model) createTask() tea.Cmd {
 		return taskCreatedMsg{}
 	}
 }
-
-func calculateNextRun(schedule string) time.Time {
-	now := time.Now()
-
-	// Handle special aliases
-	switch schedule {
-	case "@hourly":
-		return now.Add(time.Hour)
-	case "@daily":
-		// Next day at midnight
-		next := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
-		return next
-	case "@weekly":
-		// Next Sunday at midnight
-		daysUntilSunday := (7 - int(now.Weekday())) % 7
-		if daysUntilSunday == 0 {
-			daysUntilSunday = 7
-		}
-		next := time.Date(now.Year(), now.Month(), now.Day()+daysUntilSunday, 0, 0, 0, 0, now.Location())
-		return next
-	case "@monthly":
-		// First day of next month at midnight
-		next := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location())
-		return next
-	default:
-		// For now, just return next hour for cron expressions
-		// TODO: Implement proper cron parsing
-		return now.Add(time.Hour)
-	}
-}
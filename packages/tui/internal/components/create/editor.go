@@ -0,0 +1,75 @@
+// ABOUTME: External editor support for composing long prompts in $VISUAL/$EDITOR
+// ABOUTME: Suspends the TUI, edits a temp markdown file and reads the result back
+
+package create
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+)
+
+// editorName returns the user's preferred editor command line. Blank
+// values are skipped, so there's always a command to run.
+func editorName() string {
+	if editor := strings.TrimSpace(os.Getenv("VISUAL")); editor != "" {
+		return editor
+	}
+	if editor := strings.TrimSpace(os.Getenv("EDITOR")); editor != "" {
+		return editor
+	}
+	return "vi"
+}
+
+type editorFinishedMsg struct {
+	content string
+	err     error
+}
+
+// openEditor writes the current prompt to a temp file and opens it in the
+// user's editor, handing the terminal over until the editor exits
+func (m Model) openEditor() tea.Cmd {
+	f, err := os.CreateTemp("", "ritual-prompt-*.md")
+	if err != nil {
+		return func() tea.Msg { return editorFinishedMsg{err: err} }
+	}
+	path := f.Name()
+
+	_, err = f.WriteString(m.promptInput.Value())
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return func() tea.Msg { return editorFinishedMsg{err: err} }
+	}
+
+	// The editor variable may carry arguments, e.g. "code --wait"
+	args := strings.Fields(editorName())
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		defer os.Remove(path)
+
+		if err != nil {
+			return editorFinishedMsg{err: err}
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return editorFinishedMsg{err: err}
+		}
+
+		return editorFinishedMsg{content: strings.TrimRight(string(content), "\n")}
+	})
+}
+
+// estimateTokens gives a rough token count for a prompt using the common
+// four-characters-per-token heuristic
+func estimateTokens(s string) int {
+	chars := utf8.RuneCountInString(s)
+	return (chars + 3) / 4
+}
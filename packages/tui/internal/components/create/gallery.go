@@ -0,0 +1,187 @@
+// ABOUTME: Template gallery for the create form
+// ABOUTME: Lists built-in and user templates and pre-fills the form from the chosen one
+
+package create
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/key"
+	"github.com/charmbracelet/bubbles/v2/textinput"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/schedule"
+	"github.com/jem-computer/ritual/tui/internal/styles"
+	"github.com/jem-computer/ritual/tui/internal/templates"
+	"github.com/jem-computer/ritual/tui/internal/theme"
+)
+
+// galleryPromptLines caps the prompt excerpt shown for the highlighted template
+const galleryPromptLines = 4
+
+// Prefill replaces the form's contents with the given task, e.g. from a
+// template or an existing task being cloned. The schedule is loaded as a
+// custom expression so any form the server accepts round-trips unchanged.
+func (m *Model) Prefill(task api.Task) {
+	m.resetForm()
+
+	m.nameInput.SetValue(task.Name)
+	m.promptInput.SetValue(task.Prompt)
+	m.modelValue = task.Model
+	m.outputValue = task.Output
+
+	m.setPreset(presetCustom)
+	m.scheduleInput.SetValue(task.Schedule)
+	m.parseSchedule()
+
+	m.syncVariables()
+	for i, name := range m.varNames {
+		m.varInputs[i].SetValue(task.Variables[name])
+	}
+
+	m.focusedField = fieldName
+	m.updateFocus()
+}
+
+// Clone pre-fills the form from an existing task as a paused copy, so the
+// variant doesn't fire before its schedule or model has been adjusted
+func (m *Model) Clone(task api.Task) {
+	original := task.Name
+	task.Name += " (copy)"
+
+	m.Prefill(task)
+	m.statusValue = "PAUSED"
+	m.prefilledFrom = "Copy of: " + original
+}
+
+// updateGallery handles keys while the template gallery is open
+func (m Model) updateGallery(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.state = stateForm
+
+	case key.Matches(msg, m.keys.Up), msg.String() == "k":
+		if m.galleryCursor > 0 {
+			m.galleryCursor--
+		}
+
+	case key.Matches(msg, m.keys.Down), msg.String() == "j":
+		if m.galleryCursor < len(m.gallery)-1 {
+			m.galleryCursor++
+		}
+
+	case msg.String() == "enter":
+		if m.galleryCursor < len(m.gallery) {
+			chosen := m.gallery[m.galleryCursor]
+			m.Prefill(chosen.Task())
+			m.prefilledFrom = "From template: " + chosen.Name
+			return m, textinput.Blink
+		}
+	}
+
+	return m, nil
+}
+
+// renderGallery lists the templates with details for the highlighted one
+func (m Model) renderGallery() string {
+	t := theme.CurrentTheme()
+
+	mutedStyle := styles.NewStyle().Foreground(t.TextMuted())
+
+	var s strings.Builder
+
+	if len(m.gallery) == 0 {
+		s.WriteString(mutedStyle.Render("Loading templates..."))
+		return s.String()
+	}
+
+	for i, tmpl := range m.gallery {
+		nameStyle := styles.NewStyle().Foreground(t.Text())
+		cursor := "  "
+		if i == m.galleryCursor {
+			nameStyle = nameStyle.Foreground(t.Primary()).Bold(true)
+			cursor = "▸ "
+		}
+
+		source := "built-in"
+		if !tmpl.Builtin() {
+			source = filepath.Base(tmpl.Path)
+		}
+
+		s.WriteString(nameStyle.Render(cursor + tmpl.Name))
+		s.WriteString("  ")
+		s.WriteString(mutedStyle.Render(source))
+		s.WriteString("\n")
+		if tmpl.Description != "" {
+			s.WriteString(mutedStyle.PaddingLeft(4).Render(tmpl.Description))
+			s.WriteString("\n")
+		}
+	}
+
+	if m.galleryCursor < len(m.gallery) {
+		s.WriteString("\n")
+		s.WriteString(m.renderTemplateDetails(m.gallery[m.galleryCursor]))
+	}
+
+	for _, err := range m.galleryErrs {
+		s.WriteString("\n")
+		s.WriteString(styles.NewStyle().Foreground(t.Warning()).Render("⚠ " + err.Error()))
+	}
+
+	s.WriteString("\n\n")
+	s.WriteString(mutedStyle.Render("↑/↓ to choose • Enter to use template • Esc to go back"))
+
+	return s.String()
+}
+
+// renderTemplateDetails shows what a template will pre-fill
+func (m Model) renderTemplateDetails(tmpl templates.Template) string {
+	t := theme.CurrentTheme()
+
+	labelStyle := styles.NewStyle().Foreground(t.TextMuted()).Width(10)
+	valueStyle := styles.NewStyle().Foreground(t.Text())
+
+	scheduleText := tmpl.Schedule
+	if parsed, err := schedule.Parse(tmpl.Schedule); err == nil {
+		scheduleText = fmt.Sprintf("%s (%s)", parsed.Description, tmpl.Schedule)
+	}
+
+	promptLines := strings.Split(tmpl.Prompt, "\n")
+	if len(promptLines) > galleryPromptLines {
+		promptLines = append(promptLines[:galleryPromptLines], "…")
+	}
+
+	rows := [][2]string{
+		{"Schedule", scheduleText},
+		{"Model", tmpl.Model},
+		{"Output", tmpl.Output},
+		{"Prompt", strings.Join(promptLines, "\n")},
+	}
+
+	var s strings.Builder
+	for _, row := range rows {
+		if row[1] == "" {
+			continue
+		}
+		s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top,
+			labelStyle.Render(row[0]),
+			valueStyle.Width(max(m.width-16, 20)).Render(row[1])))
+		s.WriteString("\n")
+	}
+	return s.String()
+}
+
+// Commands
+
+type templatesLoadedMsg struct {
+	templates []templates.Template
+	errs      []error
+}
+
+func loadTemplates() tea.Msg {
+	loaded, errs := templates.Load()
+	return templatesLoadedMsg{templates: loaded, errs: errs}
+}
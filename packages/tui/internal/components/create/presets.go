@@ -0,0 +1,143 @@
+// ABOUTME: Schedule presets for the create form's schedule picker
+// ABOUTME: Builds schedule strings from a preset plus its time and day sub-inputs
+
+package create
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type preset int
+
+const (
+	presetHourly preset = iota
+	presetDaily
+	presetWeekdays
+	presetWeekly
+	presetMonthly
+	presetCustom
+)
+
+var presetNames = []string{
+	"Hourly",
+	"Daily",
+	"Weekdays (Mon–Fri)",
+	"Weekly",
+	"Monthly",
+	"Custom (cron or natural language)",
+}
+
+var weekdays = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+// usesTime reports whether the preset takes a time of day
+func (p preset) usesTime() bool {
+	switch p {
+	case presetDaily, presetWeekdays, presetWeekly, presetMonthly:
+		return true
+	}
+	return false
+}
+
+// usesDay reports whether the preset takes a day selector. For weekly it
+// is the weekday, for monthly the day of month and for hourly the minute
+// past the hour.
+func (p preset) usesDay() bool {
+	switch p {
+	case presetHourly, presetWeekly, presetMonthly:
+		return true
+	}
+	return false
+}
+
+// dayRange returns the selectable values for the preset's day selector
+func (p preset) dayRange() (min, max int) {
+	switch p {
+	case presetHourly:
+		return 0, 59
+	case presetWeekly:
+		return 0, 6
+	case presetMonthly:
+		return 1, 28
+	}
+	return 0, 0
+}
+
+// dayLabel describes the selector value for display
+func (p preset) dayLabel(day int) string {
+	switch p {
+	case presetHourly:
+		return fmt.Sprintf(":%02d past the hour", day)
+	case presetWeekly:
+		return strings.ToUpper(weekdays[day][:1]) + weekdays[day][1:]
+	case presetMonthly:
+		return ordinal(day) + " of the month"
+	}
+	return ""
+}
+
+// build turns the preset and its sub-inputs into a schedule string,
+// preferring the server's natural-language forms where one exists
+func (p preset) build(timeValue string, day int) (string, error) {
+	var hour, minute int
+	if p.usesTime() {
+		var err error
+		if hour, minute, err = parseClock(timeValue); err != nil {
+			return "", err
+		}
+	}
+
+	switch p {
+	case presetHourly:
+		if day == 0 {
+			return "every 1 hour", nil
+		}
+		return fmt.Sprintf("%d * * * *", day), nil
+	case presetDaily:
+		return fmt.Sprintf("daily at %d:%02d", hour, minute), nil
+	case presetWeekdays:
+		return fmt.Sprintf("%d %d * * 1-5", minute, hour), nil
+	case presetWeekly:
+		return fmt.Sprintf("every %s at %d:%02d", weekdays[day], hour, minute), nil
+	case presetMonthly:
+		if day == 1 {
+			return fmt.Sprintf("monthly at %d:%02d", hour, minute), nil
+		}
+		return fmt.Sprintf("%d %d %d * *", minute, hour, day), nil
+	}
+	return "", fmt.Errorf("custom schedules are entered as text")
+}
+
+// parseClock parses a 24-hour "HH:MM" time
+func parseClock(value string) (int, int, error) {
+	parts := strings.SplitN(strings.TrimSpace(value), ":", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("time must be HH:MM, e.g. 09:00")
+	}
+
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, 0, fmt.Errorf("invalid hour: %q", parts[0])
+	}
+	minute, err := strconv.Atoi(parts[1])
+	if err != nil || minute < 0 || minute > 59 || len(parts[1]) != 2 {
+		return 0, 0, fmt.Errorf("invalid minute: %q", parts[1])
+	}
+
+	return hour, minute, nil
+}
+
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
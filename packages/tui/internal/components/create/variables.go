@@ -0,0 +1,160 @@
+// ABOUTME: Prompt template support for the create form
+// ABOUTME: Renders an input per {{var}} placeholder and previews the expanded prompt
+
+package create
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/v2/textinput"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/jem-computer/ritual/tui/internal/components/common"
+	"github.com/jem-computer/ritual/tui/internal/prompt"
+	"github.com/jem-computer/ritual/tui/internal/styles"
+	"github.com/jem-computer/ritual/tui/internal/theme"
+)
+
+// previewLines caps how much of the expanded prompt is shown
+const previewLines = 8
+
+// lastOutputPlaceholder stands in for {{last_output}} in the preview, since
+// a task that doesn't exist yet has no previous run
+const lastOutputPlaceholder = "‹output of the previous successful run›"
+
+// variableField returns the focus slot for the i-th template variable
+func variableField(i int) field {
+	return fieldVariable + field(i)
+}
+
+// variableIndex reports which template variable a focus slot belongs to
+func variableIndex(f field) (int, bool) {
+	if f < fieldVariable {
+		return 0, false
+	}
+	return int(f - fieldVariable), true
+}
+
+// syncVariables rebuilds the variable inputs from the prompt's {{var}}
+// placeholders, keeping values already typed for names that remain
+func (m *Model) syncVariables() {
+	tmpl := m.promptInput.Value()
+	m.templateErr = prompt.Validate(tmpl)
+
+	names := prompt.Variables(tmpl)
+	if strings.Join(names, "\x00") == strings.Join(m.varNames, "\x00") {
+		return
+	}
+
+	existing := make(map[string]textinput.Model, len(m.varNames))
+	for i, name := range m.varNames {
+		existing[name] = m.varInputs[i]
+	}
+
+	m.varNames = names
+	m.varInputs = make([]textinput.Model, len(names))
+	for i, name := range names {
+		input, ok := existing[name]
+		if !ok {
+			input = textinput.New()
+			input.Placeholder = "value for " + name
+			input.CharLimit = 200
+		}
+		m.varInputs[i] = input
+	}
+
+	if i, ok := variableIndex(m.focusedField); ok && i >= len(names) {
+		m.focusedField = fieldPrompt
+	}
+	m.updateFocus()
+}
+
+// variables returns the values typed for each placeholder
+func (m Model) variables() map[string]string {
+	if len(m.varNames) == 0 {
+		return nil
+	}
+
+	values := make(map[string]string, len(m.varNames))
+	for i, name := range m.varNames {
+		values[name] = m.varInputs[i].Value()
+	}
+	return values
+}
+
+// previewTimes lists the run times the preview can be rendered for: the
+// schedule's upcoming fires, or now when the schedule doesn't parse yet
+func (m Model) previewTimes() []time.Time {
+	now := common.Now()
+	if m.parsedSchedule != nil {
+		if runs := m.parsedSchedule.Upcoming(now, previewRuns); len(runs) > 0 {
+			return runs
+		}
+	}
+	return []time.Time{now.Truncate(time.Minute)}
+}
+
+// renderVariables renders an input per template variable
+func (m Model) renderVariables() string {
+	var s strings.Builder
+
+	for i, name := range m.varNames {
+		s.WriteString(m.renderSubField(name, m.varInputs[i].View(), m.focusedField == variableField(i)))
+		s.WriteString("\n")
+	}
+
+	return s.String()
+}
+
+// renderPromptPreview shows the prompt as the model would receive it for
+// the selected upcoming run
+func (m Model) renderPromptPreview() string {
+	t := theme.CurrentTheme()
+
+	mutedStyle := styles.NewStyle().
+		Foreground(t.TextMuted()).
+		PaddingLeft(2)
+
+	if m.templateErr != nil {
+		return styles.NewStyle().
+			Foreground(t.Error()).
+			PaddingLeft(2).
+			Render("✗ " + m.templateErr.Error())
+	}
+
+	times := m.previewTimes()
+	runAt := times[min(m.previewRun, len(times)-1)]
+
+	expanded, err := prompt.Render(m.promptInput.Value(), prompt.Context{
+		RunAt:      runAt,
+		Variables:  m.variables(),
+		LastOutput: lastOutputPlaceholder,
+	})
+	if err != nil {
+		return styles.NewStyle().
+			Foreground(t.Error()).
+			PaddingLeft(2).
+			Render("✗ " + err.Error())
+	}
+
+	lines := strings.Split(expanded, "\n")
+	if len(lines) > previewLines {
+		lines = append(lines[:previewLines], fmt.Sprintf("… %d more lines", len(lines)-previewLines))
+	}
+
+	bodyStyle := styles.NewStyle().
+		Foreground(t.Text()).
+		PaddingLeft(1).
+		MarginLeft(2).
+		Border(lipgloss.NormalBorder(), false, false, false, true).
+		BorderForeground(t.BorderSubtle()).
+		Width(50)
+
+	zone, _ := runAt.Zone()
+	header := fmt.Sprintf("◀ %s %s ▶  (%d/%d)", runAt.Format("Mon Jan 2, 15:04"), zone, min(m.previewRun, len(times)-1)+1, len(times))
+
+	return m.renderSubField("Run", header, m.focusedField == fieldPreview) + "\n" +
+		bodyStyle.Render(strings.Join(lines, "\n")) + "\n" +
+		mutedStyle.Render(`{{date "Monday"}} · {{env "TEAM"}} · {{var "repo"}} · {{last_output}}`)
+}
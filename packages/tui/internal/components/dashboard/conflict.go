@@ -0,0 +1,149 @@
+// ABOUTME: Conflict resolution for dashboard changes the server refused as out of date
+// ABOUTME: Shows your version and the server's side by side and keeps whichever you choose
+
+package dashboard
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/styles"
+	"github.com/jem-computer/ritual/tui/internal/theme"
+)
+
+// conflictValueLines caps how much of a long value, like a prompt, is shown
+const conflictValueLines = 6
+
+// conflictState is a change the server refused because the task changed
+// there after the dashboard read it
+type conflictState struct {
+	active bool
+	before api.Task      // the version the change was made to
+	mine   api.Task      // that version with the change
+	theirs api.Task      // the server's current version
+	patch  api.TaskPatch // the change itself
+}
+
+// differing returns the fields where the two versions disagree
+func (c conflictState) differing() []api.TaskField {
+	var fields []api.TaskField
+	for _, f := range api.TaskFields {
+		if f.Value(c.mine) != f.Value(c.theirs) {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// changed reports whether the user's change touched f
+func (c conflictState) changed(f api.TaskField) bool {
+	return f.Value(c.before) != f.Value(c.mine)
+}
+
+// merged is the server's version with the user's patch applied on top,
+// so keeping yours doesn't undo their edits to other fields
+func (c conflictState) merged() api.Task {
+	return c.patch.Apply(c.theirs)
+}
+
+// openConflict shows the server's version in the list and, unless it
+// already matches the change, asks which to keep
+func (m *Model) openConflict(msg taskConflictMsg) tea.Cmd {
+	delete(m.pending, msg.mine.ID)
+	m.tasks, _ = replaced(m.tasks, msg.theirs)
+	cmd := m.refreshItems()
+
+	conflict := conflictState{active: true, before: msg.before, mine: msg.mine, theirs: msg.theirs, patch: msg.patch}
+	if len(conflict.differing()) == 0 {
+		// The server already has it, e.g. a retried request had gone through
+		return tea.Batch(cmd, m.saveCache())
+	}
+	m.conflict = conflict
+	return cmd
+}
+
+// updateConflict handles keys while the conflict screen is open
+func (m Model) updateConflict(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "m":
+		theirs, patch, merged := m.conflict.theirs, m.conflict.patch, m.conflict.merged()
+		m.conflict = conflictState{}
+		cmd := m.applyOptimistic(theirs, &merged)
+		return m, tea.Batch(cmd, m.updateTask(theirs, patch))
+
+	case "t", "esc":
+		name := m.conflict.theirs.Name
+		m.conflict = conflictState{}
+		return m, m.list.NewStatusMessage("Kept the server's version of " + name)
+	}
+	return m, nil
+}
+
+// renderConflict lays out the differing fields of both versions side by side
+func (m Model) renderConflict() string {
+	t := theme.CurrentTheme()
+	c := m.conflict
+
+	labelWidth := 12
+	valueWidth := max((m.width-4-labelWidth-4)/2, 10)
+
+	labelStyle := styles.NewStyle().Foreground(t.TextMuted()).Width(labelWidth)
+	headStyle := styles.NewStyle().Foreground(t.Primary()).Bold(true).Width(valueWidth).MarginRight(2)
+	valueStyle := styles.NewStyle().Foreground(t.Text()).Width(valueWidth).MarginRight(2)
+	changedStyle := valueStyle.Foreground(t.Accent())
+
+	var rows []string
+	rows = append(rows,
+		styles.NewStyle().Foreground(t.Warning()).Bold(true).
+			Render(fmt.Sprintf("⚠ %s was changed on the server after you loaded it", c.theirs.Name)),
+		styles.NewStyle().Foreground(t.TextMuted()).
+			Render("Your change wasn't saved. These fields differ (• marks what you changed):"),
+		"",
+		lipgloss.JoinHorizontal(lipgloss.Top, labelStyle.Render(""), headStyle.Render("Yours"), headStyle.Render("Server")),
+	)
+
+	for _, f := range c.differing() {
+		label, mine := f.Label, valueStyle
+		if c.changed(f) {
+			label = "• " + label
+			mine = changedStyle
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top,
+			labelStyle.Render(label),
+			mine.Render(clipLines(f.Value(c.mine), conflictValueLines)),
+			valueStyle.Render(clipLines(f.Value(c.theirs), conflictValueLines)),
+		))
+	}
+
+	rows = append(rows, "",
+		styles.NewStyle().Foreground(t.TextMuted()).
+			Render("[m] keep mine, on top of their other changes • [t] or esc keep theirs"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// clipLines keeps the first n lines of s, marking the cut
+func clipLines(s string, n int) string {
+	if s == "" {
+		return "—"
+	}
+	lines := strings.Split(s, "\n")
+	if len(lines) <= n {
+		return s
+	}
+	return strings.Join(lines[:n], "\n") + "\n…"
+}
+
+// Commands
+
+// taskConflictMsg carries a change refused as out of date, with the
+// server's current version of the task
+type taskConflictMsg struct {
+	before api.Task
+	mine   api.Task
+	theirs api.Task
+	patch  api.TaskPatch
+}
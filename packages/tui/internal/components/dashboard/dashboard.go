@@ -4,22 +4,82 @@
 package dashboard
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/v2/key"
 	"github.com/charmbracelet/bubbles/v2/list"
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
 	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/components/common"
+	"github.com/jem-computer/ritual/tui/internal/offline"
+	"github.com/jem-computer/ritual/tui/internal/ritualfile"
+	"github.com/jem-computer/ritual/tui/internal/schedule"
 	"github.com/jem-computer/ritual/tui/internal/styles"
+	"github.com/jem-computer/ritual/tui/internal/templates"
 	"github.com/jem-computer/ritual/tui/internal/theme"
 )
 
+// Thresholds that decide how a task row is highlighted
+const (
+	imminentWindow = time.Minute     // countdown below this is "about to fire"
+	runningWindow  = 5 * time.Minute // fire time passed this recently without a new LastRun is "running"
+	resyncInterval = 15 * time.Second
+
+	// reconnectInterval is how often loading is retried while the server
+	// is unreachable; the client's circuit breaker keeps this cheap
+	reconnectInterval = 3 * time.Second
+)
+
+// runPhase describes where a task is relative to its next fire time
+type runPhase int
+
+const (
+	phaseIdle runPhase = iota
+	phaseImminent
+	phaseRunning
+	phasePaused
+)
+
+// CloneTaskMsg asks the parent to open the create form pre-filled from Task
+type CloneTaskMsg struct {
+	Task api.Task
+}
+
 // taskItem implements list.Item interface for api.Task
 type taskItem struct {
-	task api.Task
+	task    api.Task
+	now     time.Time
+	fireAt  time.Time // see fireTime
+	history *taskHistory
+	queued  bool // changed offline, not yet sent to the server
+	saving  bool // a change is on its way to the server
+}
+
+// newTaskItem returns the row for task at the clock reading now
+func newTaskItem(task api.Task, now time.Time) taskItem {
+	return taskItem{task: task, now: now, fireAt: fireTime(task, now)}
+}
+
+// fireTime works out from the task's schedule when it fires, since the
+// server doesn't keep NextRun up to date: the fire time within the last
+// runningWindow while its run isn't recorded yet, otherwise the next one.
+// It's zero when the schedule doesn't parse or never fires.
+func fireTime(task api.Task, now time.Time) time.Time {
+	s, err := schedule.Parse(task.Schedule)
+	if err != nil {
+		return time.Time{}
+	}
+	if last := s.Next(now.Add(-runningWindow)); !last.After(now) && task.LastRun.Before(last) {
+		return last
+	}
+	return s.Next(now)
 }
 
 func (i taskItem) FilterValue() string {
@@ -30,19 +90,94 @@ func (i taskItem) Title() string {
 	return i.task.Name
 }
 
+// phase reports the task's run phase at the item's clock reading
+func (i taskItem) phase() runPhase {
+	if i.task.Status == "PAUSED" {
+		return phasePaused
+	}
+	if i.fireAt.IsZero() {
+		return phaseIdle
+	}
+
+	until := i.fireAt.Sub(i.now)
+	switch {
+	case until > 0 && until <= imminentWindow:
+		return phaseImminent
+	case until <= 0 && -until < runningWindow && i.task.LastRun.Before(i.fireAt):
+		return phaseRunning
+	default:
+		return phaseIdle
+	}
+}
+
 func (i taskItem) Description() string {
-	status := i.task.Status
-	if status == "PAUSED" {
+	var status string
+	switch i.phase() {
+	case phasePaused:
 		status = "⏸ PAUSED"
-	} else {
+	case phaseRunning:
+		status = "● RUNNING"
+	default:
 		status = "▶ ACTIVE"
 	}
 
-	nextRun := "Next: " + i.task.NextRun.Format("Jan 2, 3:04 PM")
-	if i.task.LastRun.IsZero() {
-		return fmt.Sprintf("%s • %s • Never run", status, nextRun)
+	var nextRun string
+	switch {
+	case i.task.Status == "PAUSED":
+		nextRun = "Next: —"
+	case i.fireAt.IsZero():
+		nextRun = "Next: not scheduled"
+	case i.phase() == phaseRunning:
+		nextRun = "Started " + common.Ago(i.now.Sub(i.fireAt))
+	default:
+		nextRun = "Next: " + common.RelativeTime(i.fireAt, i.now)
+	}
+
+	lastRun := "Never run"
+	if !i.task.LastRun.IsZero() {
+		lastRun = "Last: " + common.RelativeTime(i.task.LastRun, i.now)
 	}
-	return fmt.Sprintf("%s • %s • Last: %s", status, nextRun, i.task.LastRun.Format("Jan 2, 3:04 PM"))
+
+	desc := fmt.Sprintf("%s • %s • %s", status, nextRun, lastRun)
+	if i.task.ManagedBy != "" {
+		// Owned by a definition file; edits here are overwritten by the next apply
+		desc += " • ⚙ " + i.task.ManagedBy
+	}
+	switch {
+	case i.saving:
+		desc += " • ⟳ saving…"
+	case i.queued:
+		desc += " • ⧗ not synced"
+	}
+	return desc
+}
+
+// sortTasks orders tasks by urgency: running first, then active tasks by
+// next fire time, then unscheduled and paused tasks, ties broken by name.
+func sortTasks(items []taskItem) {
+	rank := func(i taskItem) int {
+		switch i.phase() {
+		case phaseRunning:
+			return 0
+		case phasePaused:
+			return 3
+		}
+		if i.fireAt.IsZero() {
+			return 2
+		}
+		return 1
+	}
+
+	sort.SliceStable(items, func(a, b int) bool {
+		ra, rb := rank(items[a]), rank(items[b])
+		if ra != rb {
+			return ra < rb
+		}
+		if ra <= 1 && !items[a].fireAt.Equal(items[b].fireAt) {
+			return items[a].fireAt.Before(items[b].fireAt)
+		}
+		return items[a].task.Name < items[b].task.Name
+	})
 }
 
 // Custom item delegate for better control over rendering
@@ -65,6 +200,15 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 
 	var s strings.Builder
 
+	// Rows about to fire or currently running get an accent on their status line
+	descColor := t.TextMuted()
+	switch i.phase() {
+	case phaseImminent:
+		descColor = t.Warning()
+	case phaseRunning:
+		descColor = t.Success()
+	}
+
 	if index == m.Index() {
 		// Selected item - add a bullet point and highlight
 		title := styles.NewStyle().
@@ -72,7 +216,7 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 			Bold(true).
 			Render("✦ " + i.Title())
 		desc := styles.NewStyle().
-			Foreground(t.TextMuted()).
+			Foreground(descColor).
 			PaddingLeft(2).
 			MarginBottom(1).
 			Render(i.Description())
@@ -86,7 +230,7 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 			PaddingLeft(2).
 			Render(i.Title())
 		desc := styles.NewStyle().
-			Foreground(t.TextMuted()).
+			Foreground(descColor).
 			PaddingLeft(2).
 			MarginBottom(1).
 			Render(i.Description())
@@ -95,26 +239,80 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 		s.WriteString(desc)
 	}
 
-	fmt.Fprint(w, s.String())
+	fmt.Fprint(w, withHistory(s.String(), i.history, m.Width()))
+}
+
+// withHistory right-aligns the run history summary on the row's title line
+func withHistory(row string, history *taskHistory, width int) string {
+	if history == nil {
+		return row
+	}
+
+	lines := strings.SplitN(row, "\n", 2)
+	summary := history.Summary()
+	gap := width - lipgloss.Width(lines[0]) - lipgloss.Width(summary)
+	if gap < 1 {
+		return row
+	}
+
+	lines[0] += strings.Repeat(" ", gap) + summary
+	return strings.Join(lines, "\n")
 }
 
 type Model struct {
-	client *api.Client
-	list   list.Model
-	tasks  []api.Task
-	width  int
-	height int
-	keys   keyMap
-	err    error
+	client   *api.Client
+	list     list.Model
+	tasks    []api.Task
+	now      time.Time
+	syncedAt time.Time
+	width    int
+	height   int
+	keys     keyMap
+	err      error
+
+	// reconnecting is set while loads fail because the server is away; the
+	// last tasks stay on screen and loading is retried in the background
+	reconnecting bool
+	retriedAt    time.Time
+
+	// Run history per task ID, refetched only when a task's LastRun changes
+	history        map[string]taskHistory
+	historyPending map[string]context.CancelFunc // cancels the in-flight fetch
+	logs           map[string][]api.LogEntry     // the fetched executions, kept for the offline cache
+
+	// cached is set while the tasks on screen come from the offline cache
+	// and the server hasn't confirmed them yet
+	cached bool
+	// Changes made while offline, replayed once the server is back
+	queue     []offline.Change
+	replaying bool
+
+	// Changes shown ahead of the server's answer, by task ID
+	pending map[string]pendingChange
+	toast   common.Toast
+
+	// A change refused because the task changed on the server meanwhile
+	conflict conflictState
+
+	// In-progress export or import
+	transfer transferState
+
+	// The run history of one task, opened from its row
+	runs runsState
 }
 
 type keyMap struct {
-	Up     key.Binding
-	Down   key.Binding
-	Enter  key.Binding
-	Delete key.Binding
-	Pause  key.Binding
-	Retry  key.Binding
+	Up       key.Binding
+	Down     key.Binding
+	Enter    key.Binding
+	Delete   key.Binding
+	Pause    key.Binding
+	Retry    key.Binding
+	Template key.Binding
+	Clone    key.Binding
+	History  key.Binding
+	Export   key.Binding
+	Import   key.Binding
 }
 
 func defaultKeyMap() keyMap {
@@ -143,6 +341,27 @@ func defaultKeyMap() keyMap {
 			key.WithKeys("r"),
 			key.WithHelp("r", "retry"),
 		),
+		Template: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "save as template"),
+		),
+		Clone: key.NewBinding(
+			key.WithKeys("C"),
+			key.WithHelp("C", "clone"),
+		),
+		History: key.NewBinding(
+			// Plain l is taken by the Logs tab
+			key.WithKeys("L"),
+			key.WithHelp("L", "run history"),
+		),
+		Export: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "export"),
+		),
+		Import: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "import"),
+		),
 	}
 }
 
@@ -168,6 +387,7 @@ func New(client *api.Client) Model {
 			keys.Enter,
 			keys.Pause,
 			keys.Delete,
+			keys.History,
 		}
 	}
 	l.AdditionalFullHelpKeys = func() []key.Binding {
@@ -175,6 +395,11 @@ func New(client *api.Client) Model {
 			keys.Enter,
 			keys.Pause,
 			keys.Delete,
+			keys.History,
+			keys.Clone,
+			keys.Template,
+			keys.Export,
+			keys.Import,
 			keys.Retry,
 		}
 	}
@@ -182,11 +407,18 @@ func New(client *api.Client) Model {
 	return Model{
 		client: client,
 		list:   l,
+		now:    common.Now(),
 		keys:   keys,
+
+		history:        make(map[string]taskHistory),
+		historyPending: make(map[string]context.CancelFunc),
+		logs:           make(map[string][]api.LogEntry),
+		pending:        make(map[string]pendingChange),
 	}
 }
+
 func (m Model) Init() (tea.Model, tea.Cmd) {
-	return m, m.loadTasks
+	return m, tea.Batch(m.loadCache, m.loadTasks, tick())
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -204,16 +436,72 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.list.SetSize(m.width-4, listHeight)
 
 	case tea.KeyMsg:
+		if m.transfer.mode != transferNone {
+			return m.updateTransfer(msg)
+		}
+		if m.conflict.active {
+			return m.updateConflict(msg)
+		}
+		if m.runs.active {
+			return m.updateRuns(msg)
+		}
+
 		// Handle custom keybindings first
 		switch {
 		case key.Matches(msg, m.keys.Delete):
 			if selectedItem, ok := m.list.SelectedItem().(taskItem); ok {
-				return m, m.deleteTask(selectedItem.task.ID)
+				if m.reconnecting {
+					cmd := m.queueChange(offlineChange(offline.ChangeDelete, selectedItem.task))
+					return m, cmd
+				}
+				if _, busy := m.pending[selectedItem.task.ID]; busy {
+					return m, nil
+				}
+				cmd := m.applyOptimistic(selectedItem.task, nil)
+				return m, tea.Batch(cmd, m.deleteTask(selectedItem.task))
 			}
 
 		case key.Matches(msg, m.keys.Pause):
 			if selectedItem, ok := m.list.SelectedItem().(taskItem); ok {
-				return m, m.toggleTaskStatus(selectedItem.task.ID)
+				before, patch := selectedItem.task, toggled(selectedItem.task)
+				if m.reconnecting {
+					cmd := m.queueChange(offlineUpdate(before, patch))
+					return m, cmd
+				}
+				// One request per task at a time, so rollbacks stay in order
+				if _, busy := m.pending[before.ID]; busy {
+					return m, nil
+				}
+				after := patch.Apply(before)
+				cmd := m.applyOptimistic(before, &after)
+				return m, tea.Batch(cmd, m.updateTask(before, patch))
+			}
+
+		case key.Matches(msg, m.keys.Clone):
+			if selectedItem, ok := m.list.SelectedItem().(taskItem); ok {
+				task := selectedItem.task
+				return m, func() tea.Msg { return CloneTaskMsg{Task: task} }
+			}
+
+		case key.Matches(msg, m.keys.History):
+			if selectedItem, ok := m.list.SelectedItem().(taskItem); ok {
+				return m, m.openRuns(selectedItem.task)
+			}
+
+		case key.Matches(msg, m.keys.Export):
+			if len(m.tasks) > 0 {
+				return m, m.startTransfer(transferExportPath)
+			}
+
+		case key.Matches(msg, m.keys.Import):
+			if m.reconnecting {
+				return m, m.list.NewStatusMessage("Read-only while offline: importing needs the server")
+			}
+			return m, m.startTransfer(transferImportPath)
+
+		case key.Matches(msg, m.keys.Template):
+			if selectedItem, ok := m.list.SelectedItem().(taskItem); ok {
+				return m, saveTemplate(selectedItem.task)
 			}
 
 		case key.Matches(msg, m.keys.Retry):
@@ -223,23 +511,149 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case tickMsg:
+		m.now = time.Time(msg)
+		cmds = append(cmds, m.refreshItems(), tick())
+
+		// Pick up LastRun from the server while something is running,
+		// without polling every second
+		if m.err == nil && m.anyRunning() && m.now.Sub(m.syncedAt) >= resyncInterval {
+			m.syncedAt = m.now
+			cmds = append(cmds, m.loadTasks)
+		}
+		if m.reconnecting && m.now.Sub(m.retriedAt) >= reconnectInterval {
+			m.retriedAt = m.now
+			cmds = append(cmds, m.loadTasks)
+		}
+		return m, tea.Batch(cmds...)
+
+	case common.ConnectionMsg:
+		if msg.State == api.Connected && (m.reconnecting || api.IsUnauthorized(m.err)) {
+			m.err = nil
+			return m, m.loadTasks
+		}
+		return m, nil
+
 	case tasksLoadedMsg:
-		m.tasks = msg.tasks
-		// Convert tasks to list items
-		items := make([]list.Item, len(m.tasks))
-		for i, task := range m.tasks {
-			items[i] = taskItem{task: task}
+		m.reconnecting = false
+		m.cached = false
+		m.tasks = offline.Apply(withPending(msg.tasks, m.pending), m.queue)
+		m.syncedAt = common.Now()
+		cmds = append(cmds, m.refreshItems())
+		if len(m.queue) > 0 {
+			cmds = append(cmds, m.replayQueue())
+		} else {
+			cmds = append(cmds, m.saveCache())
 		}
-		cmd := m.list.SetItems(items)
-		cmds = append(cmds, cmd)
+
+		pruneHistory(m.tasks, m.history)
+		pruneHistory(m.tasks, m.logs)
+		cancelHistory(m.tasks, m.historyPending)
+		for _, task := range staleHistory(m.tasks, m.history) {
+			if _, pending := m.historyPending[task.ID]; pending {
+				continue
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			m.historyPending[task.ID] = cancel
+			cmds = append(cmds, m.loadHistory(ctx, task))
+		}
+
+	case historyLoadedMsg:
+		if cancel, ok := m.historyPending[msg.taskID]; ok {
+			cancel()
+			delete(m.historyPending, msg.taskID)
+		}
+		// The task was deleted while its history was loading
+		if errors.Is(msg.history.err, context.Canceled) {
+			return m, nil
+		}
+		m.history[msg.taskID] = msg.history
+		if msg.history.err == nil {
+			m.logs[msg.taskID] = msg.logs
+		}
+		cmds = append(cmds, m.refreshItems())
+		if len(m.historyPending) == 0 {
+			// Cache the logs once the whole batch is in rather than per task
+			cmds = append(cmds, m.saveCache())
+		}
+
+	case cacheLoadedMsg:
+		return m, m.applyCache(msg.snapshot)
+
+	case changeQueuedMsg:
+		delete(m.pending, msg.change.Task.ID)
+		return m, m.queueChange(msg.change)
+
+	case replayedMsg:
+		return m, m.replayed(msg)
 
 	case taskDeletedMsg:
-		return m, m.loadTasks
+		return m, tea.Batch(m.settle(msg.id, nil), m.saveCache())
 
 	case taskUpdatedMsg:
-		return m, m.loadTasks
+		return m, tea.Batch(m.settle(msg.task.ID, &msg.task), m.saveCache())
+
+	case actionFailedMsg:
+		return m, m.failed(msg)
+
+	case taskConflictMsg:
+		return m, m.openConflict(msg)
+
+	case runsLoadedMsg:
+		return m, m.runsLoaded(msg)
+
+	case runsPollMsg:
+		if !m.runs.waiting || msg.taskID != m.runs.task.ID {
+			return m, nil
+		}
+		m.runs.polls++
+		return m, m.loadRuns(msg.taskID)
+
+	case runQueuedMsg:
+		return m, m.runQueued(msg)
+
+	case common.ToastExpiredMsg:
+		m.toast = m.toast.Update(msg)
+		return m, nil
+
+	case taskMissingMsg:
+		// Deleted elsewhere, e.g. from another terminal or by ritual apply
+		delete(m.pending, msg.id)
+		cmds = append(cmds, m.list.NewStatusMessage(fmt.Sprintf("%s no longer exists", msg.name)), m.loadTasks)
+		return m, tea.Batch(cmds...)
+
+	case importReadMsg:
+		if len(msg.duplicates) == 0 {
+			return m, m.importTasks(msg.doc, ritualfile.Skip)
+		}
+		m.transfer = transferState{
+			mode:       transferImportDuplicates,
+			doc:        msg.doc,
+			duplicates: msg.duplicates,
+		}
+		return m, nil
+
+	case transferDoneMsg:
+		cmds = append(cmds, m.list.NewStatusMessage(msg.message))
+		if msg.reload {
+			cmds = append(cmds, m.loadTasks)
+		}
+		return m, tea.Batch(cmds...)
+
+	case templateSavedMsg:
+		if msg.err != nil {
+			return m, m.list.NewStatusMessage(fmt.Sprintf("Couldn't save template: %v", msg.err))
+		}
+		return m, m.list.NewStatusMessage("Saved template to " + msg.path)
 
 	case errorMsg:
+		if common.Transient(msg.err, m.client.ConnState()) {
+			m.reconnecting = true
+			m.retriedAt = common.Now()
+			return m, nil
+		}
+		m.reconnecting = false
+		m.cached = false
 		m.err = msg.err
 		// Clear tasks on error
 		m.tasks = []api.Task{}
@@ -273,6 +687,9 @@ func (m Model) View() string {
 		Bold(true)
 
 	s.WriteString(headerStyle.Render("> SCHEDULED TASKS"))
+	if status := m.syncStatus(); status != "" {
+		s.WriteString("  " + status)
+	}
 	s.WriteString("\n\n")
 
 	// NEW TASK button at bottom right
@@ -284,14 +701,37 @@ func (m Model) View() string {
 
 	s.WriteString(lipgloss.PlaceHorizontal(m.width-4, lipgloss.Left, buttonStyle.Render("+ NEW TASK")))
 
-	if m.err != nil {
+	if m.transfer.mode != transferNone {
+		s.WriteString("\n")
+		s.WriteString(m.renderTransfer())
+		s.WriteString("\n")
+	}
+
+	if m.conflict.active {
+		s.WriteString("\n")
+		s.WriteString(m.renderConflict())
+	} else if m.runs.active {
+		s.WriteString("\n\n")
+		s.WriteString(m.renderRuns())
+	} else if m.err != nil {
 		// Error state
 		errorStyle := styles.NewStyle().
 			Foreground(t.Error()).
 			Width(m.width-4).
 			Height(m.height-10).
 			Align(lipgloss.Center, lipgloss.Center)
-		s.WriteString(errorStyle.Render(fmt.Sprintf("Error loading tasks:\n%v\n\nPress [R] to retry", m.err)))
+		if api.IsUnauthorized(m.err) {
+			s.WriteString(errorStyle.Render(fmt.Sprintf("Not authorized:\n%v\n\nPress ctrl+l to log in", m.err)))
+		} else {
+			s.WriteString(errorStyle.Render(fmt.Sprintf("Error loading tasks:\n%v\n\nPress [R] to retry", m.err)))
+		}
+	} else if len(m.tasks) == 0 && m.reconnecting {
+		waitingStyle := styles.NewStyle().
+			Foreground(t.Warning()).
+			Width(m.width-4).
+			Height(m.height-10).
+			Align(lipgloss.Center, lipgloss.Center)
+		s.WriteString(waitingStyle.Render("Waiting for the server…\n\nTasks will appear once it's reachable"))
 	} else if len(m.tasks) == 0 {
 		// Empty state
 		emptyStyle := styles.NewStyle().
@@ -305,6 +745,11 @@ func (m Model) View() string {
 		s.WriteString(m.list.View())
 	}
 
+	if m.toast.Visible() {
+		s.WriteString("\n")
+		s.WriteString(lipgloss.PlaceHorizontal(m.width-4, lipgloss.Right, m.toast.View(m.width-4)))
+	}
+
 	// Calculate remaining space
 	contentHeight := lipgloss.Height(s.String())
 	remainingHeight := m.height - contentHeight - 3 // Leave space for NEW TASK button
@@ -316,15 +761,87 @@ func (m Model) View() string {
 	return s.String()
 }
 
+// refreshItems rebuilds the list items at the current clock reading and
+// re-sorts them, keeping the cursor on the same task.
+func (m *Model) refreshItems() tea.Cmd {
+	var selectedID string
+	if selectedItem, ok := m.list.SelectedItem().(taskItem); ok {
+		selectedID = selectedItem.task.ID
+	}
+
+	sorted := make([]taskItem, len(m.tasks))
+	for i, task := range m.tasks {
+		_, queued := offline.Pending(m.queue, task.ID)
+		_, saving := m.pending[task.ID]
+		sorted[i] = newTaskItem(task, m.now)
+		sorted[i].queued, sorted[i].saving = queued, saving
+		if h, ok := m.history[task.ID]; ok {
+			sorted[i].history = &h
+		}
+	}
+	sortTasks(sorted)
+
+	items := make([]list.Item, len(sorted))
+	selected := -1
+	for i, item := range sorted {
+		items[i] = item
+		if item.task.ID == selectedID {
+			selected = i
+		}
+	}
+
+	cmd := m.list.SetItems(items)
+	if selected >= 0 {
+		m.list.Select(selected)
+	}
+	return cmd
+}
+
+// SelectTask moves the cursor to the task with the given ID, if present
+func (m *Model) SelectTask(id string) {
+	for i, item := range m.list.Items() {
+		if ti, ok := item.(taskItem); ok && ti.task.ID == id {
+			m.list.Select(i)
+			return
+		}
+	}
+}
+
+// anyRunning reports whether any task is inside its running window
+func (m Model) anyRunning() bool {
+	for _, task := range m.tasks {
+		if newTaskItem(task, m.now).phase() == phaseRunning {
+			return true
+		}
+	}
+	return false
+}
+
 // Commands
 
+type tickMsg time.Time
+
+func tick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
 type tasksLoadedMsg struct {
 	tasks []api.Task
 }
 
-type taskDeletedMsg struct{}
+// taskMissingMsg reports that the server no longer has a task the list
+// still shows
+type taskMissingMsg struct {
+	id   string
+	name string
+}
 
-type taskUpdatedMsg struct{}
+type templateSavedMsg struct {
+	path string
+	err  error
+}
 
 type errorMsg struct {
 	err error
@@ -339,42 +856,20 @@ func (m Model) loadTasks() tea.Msg {
 	return tasksLoadedMsg{tasks: tasks}
 }
 
-func (m Model) deleteTask(id string) tea.Cmd {
-	return func() tea.Msg {
-		err := m.client.DeleteTask(id)
-		if err != nil {
-			return errorMsg{err: err}
-		}
-		return taskDeletedMsg{}
+// toggled returns the patch that pauses task if it's active, or resumes it
+// otherwise
+func toggled(task api.Task) api.TaskPatch {
+	if task.Status == "ACTIVE" {
+		return api.Patch().Status("PAUSED")
 	}
+	return api.Patch().Status("ACTIVE")
 }
 
-func (m Model) toggleTaskStatus(id string) tea.Cmd {
+// saveTemplate writes the task to the user template library. Failures are
+// reported in the status bar rather than replacing the task list.
+func saveTemplate(task api.Task) tea.Cmd {
 	return func() tea.Msg {
-		// Find the task
-		var task *api.Task
-		for _, t := range m.tasks {
-			if t.ID == id {
-				task = &t
-				break
-			}
-		}
-
-		if task == nil {
-			return nil
-		}
-
-		// Toggle status
-		if task.Status == "ACTIVE" {
-			task.Status = "PAUSED"
-		} else {
-			task.Status = "ACTIVE"
-		}
-
-		_, err := m.client.UpdateTask(id, *task)
-		if err != nil {
-			return errorMsg{err: err}
-		}
-		return taskUpdatedMsg{}
+		path, err := templates.Save(templates.FromTask(task))
+		return templateSavedMsg{path: path, err: err}
 	}
 }
@@ -0,0 +1,95 @@
+// ABOUTME: Line diff between the outputs of two runs
+// ABOUTME: Longest common subsequence over lines, enough for LLM output of a few hundred lines
+
+package dashboard
+
+import "strings"
+
+// maxDiffLines bounds the diff's table, which grows with the product of
+// both sides; longer outputs are compared on their first lines only
+const maxDiffLines = 2000
+
+// diffOp says what happened to a line between the older and newer text
+type diffOp int
+
+const (
+	diffSame diffOp = iota
+	diffAdded
+	diffRemoved
+)
+
+type diffLine struct {
+	op   diffOp
+	text string
+}
+
+// lineDiff returns the lines of older and newer merged in order, marking
+// those only in older as removed and those only in newer as added
+func lineDiff(older, newer string) []diffLine {
+	a := splitLines(older)
+	b := splitLines(newer)
+
+	// common[i][j] is the length of the longest common subsequence of
+	// a[i:] and b[j:]
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, diffLine{diffSame, a[i]})
+			i++
+			j++
+		case common[i+1][j] >= common[i][j+1]:
+			lines = append(lines, diffLine{diffRemoved, a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{diffAdded, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, diffLine{diffRemoved, a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, diffLine{diffAdded, b[j]})
+	}
+	return lines
+}
+
+// changes counts the added and removed lines of a diff
+func changes(lines []diffLine) (added, removed int) {
+	for _, line := range lines {
+		switch line.op {
+		case diffAdded:
+			added++
+		case diffRemoved:
+			removed++
+		}
+	}
+	return added, removed
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	if len(lines) > maxDiffLines {
+		lines = lines[:maxDiffLines]
+	}
+	return lines
+}
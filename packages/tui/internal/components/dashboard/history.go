@@ -0,0 +1,164 @@
+// ABOUTME: Per-task run history summaries for the dashboard rows
+// ABOUTME: Computes sparkline, success rate and median duration from cached task logs
+
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/components/common"
+)
+
+// historyLength is the number of executions shown in a row's sparkline
+const historyLength = 10
+
+// taskHistory summarizes a task's most recent executions
+type taskHistory struct {
+	// lastRun is the task's LastRun when the logs were fetched; the entry is
+	// only refetched once the server reports a newer run
+	lastRun time.Time
+
+	statuses       []string // oldest first, at most historyLength
+	runs           int
+	successes      int
+	medianDuration time.Duration
+	err            error
+}
+
+// newTaskHistory builds a summary from logs ordered newest first, as
+// returned by the server
+func newTaskHistory(lastRun time.Time, logs []api.LogEntry) taskHistory {
+	h := taskHistory{lastRun: lastRun}
+
+	if len(logs) > historyLength {
+		logs = logs[:historyLength]
+	}
+
+	durations := make([]time.Duration, 0, len(logs))
+	for i := len(logs) - 1; i >= 0; i-- {
+		log := logs[i]
+		h.statuses = append(h.statuses, log.Status)
+
+		switch log.Status {
+		case "SUCCESS":
+			h.runs++
+			h.successes++
+		case "FAILURE", "ERROR":
+			h.runs++
+		default:
+			// Skipped executions don't count towards the success rate
+			continue
+		}
+		durations = append(durations, time.Duration(log.Duration)*time.Millisecond)
+	}
+
+	h.medianDuration = median(durations)
+	return h
+}
+
+// Summary renders the sparkline followed by success rate and median duration
+func (h taskHistory) Summary() string {
+	spark := common.RunSparkline(h.statuses, historyLength)
+	if h.err != nil {
+		return spark + " history unavailable"
+	}
+	if h.runs == 0 {
+		return spark + " no runs"
+	}
+
+	rate := h.successes * 100 / h.runs
+	return fmt.Sprintf("%s %3d%% · %s", spark, rate, formatDuration(h.medianDuration))
+}
+
+// staleHistory returns the tasks whose cached history is missing or older
+// than their last run
+func staleHistory(tasks []api.Task, cache map[string]taskHistory) []api.Task {
+	var stale []api.Task
+	for _, task := range tasks {
+		h, ok := cache[task.ID]
+		if !ok || !h.lastRun.Equal(task.LastRun) {
+			stale = append(stale, task)
+		}
+	}
+	return stale
+}
+
+// cancelHistory stops in-flight fetches for tasks that no longer exist
+func cancelHistory(tasks []api.Task, pending map[string]context.CancelFunc) {
+	known := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		known[task.ID] = true
+	}
+	for id, cancel := range pending {
+		if !known[id] {
+			cancel()
+		}
+	}
+}
+
+// pruneHistory drops cached entries for tasks that no longer exist
+func pruneHistory[V any](tasks []api.Task, cache map[string]V) {
+	known := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		known[task.ID] = true
+	}
+	for id := range cache {
+		if !known[id] {
+			delete(cache, id)
+		}
+	}
+}
+
+func median(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a] < sorted[b] })
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
+
+// Commands
+
+type historyLoadedMsg struct {
+	taskID  string
+	history taskHistory
+	logs    []api.LogEntry
+}
+
+func (m Model) loadHistory(ctx context.Context, task api.Task) tea.Cmd {
+	return func() tea.Msg {
+		logs, err := m.client.GetTaskLogsContext(ctx, task.ID)
+		if err != nil {
+			// Cache the failure too so a broken endpoint isn't retried on
+			// every refresh; it is retried once the task runs again
+			return historyLoadedMsg{
+				taskID:  task.ID,
+				history: taskHistory{lastRun: task.LastRun, err: err},
+			}
+		}
+		return historyLoadedMsg{
+			taskID:  task.ID,
+			history: newTaskHistory(task.LastRun, logs),
+			logs:    logs,
+		}
+	}
+}
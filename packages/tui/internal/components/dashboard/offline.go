@@ -0,0 +1,195 @@
+// ABOUTME: Offline support for the dashboard: cached tasks and queued changes
+// ABOUTME: Shows the last synced tasks while the server is away and replays changes once it's back
+
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/components/common"
+	"github.com/jem-computer/ritual/tui/internal/offline"
+	"github.com/jem-computer/ritual/tui/internal/styles"
+	"github.com/jem-computer/ritual/tui/internal/theme"
+)
+
+// cacheLoadedMsg delivers the snapshot saved by the last session
+type cacheLoadedMsg struct {
+	snapshot offline.Snapshot
+}
+
+// changeQueuedMsg reports a change that couldn't reach the server and
+// should be queued instead
+type changeQueuedMsg struct {
+	change offline.Change
+}
+
+// replayedMsg reports the outcome of replaying the queue
+type replayedMsg struct {
+	sent      int // length of the queue when the replay started
+	outcomes  []offline.Outcome
+	remaining []offline.Change
+}
+
+func (m Model) loadCache() tea.Msg {
+	// An unreadable cache is treated as empty; the server is the source of truth
+	snapshot, _ := offline.Load(m.client.BaseURL())
+	return cacheLoadedMsg{snapshot: snapshot}
+}
+
+// saveCache persists the tasks on screen, their logs and the queue
+func (m Model) saveCache() tea.Cmd {
+	snapshot := offline.Snapshot{
+		Server:   m.client.BaseURL(),
+		SyncedAt: m.syncedAt,
+		Tasks:    m.tasks,
+		Logs:     maps.Clone(m.logs),
+		Queue:    m.queue,
+	}
+	return func() tea.Msg {
+		// Best effort: failing to cache only matters on the next offline start
+		snapshot.Save()
+		return nil
+	}
+}
+
+// applyCache shows the cached tasks until the server answers. Changes
+// queued last session are kept either way and replayed once it does.
+func (m *Model) applyCache(snapshot offline.Snapshot) tea.Cmd {
+	if len(m.queue) == 0 {
+		m.queue = snapshot.Queue
+	}
+
+	if !m.syncedAt.IsZero() {
+		// The server answered first
+		return m.replayQueue()
+	}
+	if len(snapshot.Tasks) == 0 {
+		return nil
+	}
+
+	m.cached = true
+	m.syncedAt = snapshot.SyncedAt
+	m.tasks = offline.Apply(snapshot.Tasks, m.queue)
+	if snapshot.Logs != nil {
+		m.logs = snapshot.Logs
+	}
+	for _, task := range m.tasks {
+		if logs, ok := m.logs[task.ID]; ok {
+			m.history[task.ID] = newTaskHistory(task.LastRun, logs)
+		}
+	}
+	return m.refreshItems()
+}
+
+// queueChange applies a change locally and queues it for the server
+func (m *Model) queueChange(change offline.Change) tea.Cmd {
+	m.queue = offline.Enqueue(m.queue, change)
+	m.tasks = offline.Apply(m.tasks, []offline.Change{change})
+	return tea.Batch(
+		m.refreshItems(),
+		m.saveCache(),
+		m.list.NewStatusMessage(fmt.Sprintf("Offline: will %s once the server is back", change.Describe())),
+	)
+}
+
+// offlineChange builds the change to queue for task, as it is now
+func offlineChange(kind offline.ChangeKind, task api.Task) offline.Change {
+	return offline.Change{Kind: kind, Task: task, Base: task, QueuedAt: time.Now()}
+}
+
+// offlineUpdate builds the change to queue for patching before
+func offlineUpdate(before api.Task, patch api.TaskPatch) offline.Change {
+	change := offlineChange(offline.ChangeUpdate, before)
+	change.Task = patch.Apply(before)
+	change.Patch = patch
+	return change
+}
+
+// replayQueue sends the queued changes, one replay at a time
+func (m *Model) replayQueue() tea.Cmd {
+	if len(m.queue) == 0 || m.replaying {
+		return nil
+	}
+	m.replaying = true
+
+	client, queue := m.client, m.queue
+	return func() tea.Msg {
+		outcomes, remaining := offline.Replay(context.Background(), client, queue)
+		return replayedMsg{sent: len(queue), outcomes: outcomes, remaining: remaining}
+	}
+}
+
+// replayed records a finished replay and reports it in the status bar
+func (m *Model) replayed(msg replayedMsg) tea.Cmd {
+	m.replaying = false
+	// Keep whatever was queued while the replay ran
+	m.queue = append(msg.remaining, m.queue[min(msg.sent, len(m.queue)):]...)
+	if len(msg.outcomes) == 0 {
+		// The server went away again before anything was sent
+		return tea.Batch(m.saveCache(), m.loadTasks)
+	}
+
+	var synced int
+	var problems []string
+	for _, outcome := range msg.outcomes {
+		switch {
+		case outcome.Conflict != "":
+			problems = append(problems, outcome.Conflict+", kept the server's version")
+		case outcome.Err != nil:
+			problems = append(problems, fmt.Sprintf("couldn't %s: %v", outcome.Change.Describe(), outcome.Err))
+		default:
+			synced++
+		}
+	}
+
+	if synced > 0 {
+		problems = append([]string{fmt.Sprintf("Synced %d offline %s", synced, plural(synced, "change"))}, problems...)
+	}
+	status := strings.Join(problems, " · ")
+	return tea.Batch(m.saveCache(), m.loadTasks, m.list.NewStatusMessage(status))
+}
+
+// syncStatus renders the header note shown while the tasks on screen may
+// be out of date
+func (m Model) syncStatus() string {
+	t := theme.CurrentTheme()
+
+	synced := "never synced"
+	if !m.syncedAt.IsZero() {
+		synced = "last synced " + common.Ago(m.now.Sub(m.syncedAt))
+	}
+
+	var status string
+	color := t.TextMuted()
+	switch {
+	case m.reconnecting:
+		status = "⚠ offline · " + synced
+		color = t.Warning()
+	case m.cached:
+		status = "cached · " + synced
+	}
+	if n := len(m.queue); n > 0 {
+		if status != "" {
+			status += " · "
+		}
+		status += fmt.Sprintf("%d %s queued", n, plural(n, "change"))
+	}
+
+	if status == "" {
+		return ""
+	}
+	return styles.NewStyle().Foreground(color).Render(status)
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
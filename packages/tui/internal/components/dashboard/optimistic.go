@@ -0,0 +1,160 @@
+// ABOUTME: Optimistic task changes for the dashboard
+// ABOUTME: Applies pause/resume and delete locally at once, then settles or rolls back on the server's answer
+
+package dashboard
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/components/common"
+	"github.com/jem-computer/ritual/tui/internal/offline"
+)
+
+// pendingChange is a change shown before the server has confirmed it
+type pendingChange struct {
+	before api.Task  // the task as it was, restored if the request fails
+	after  *api.Task // the task as shown; nil for a delete
+}
+
+// applyOptimistic shows the change from before to after (nil to delete)
+// straight away and records it until the server answers
+func (m *Model) applyOptimistic(before api.Task, after *api.Task) tea.Cmd {
+	m.pending[before.ID] = pendingChange{before: before, after: after}
+	m.tasks = withPending(m.tasks, m.pending)
+	return m.refreshItems()
+}
+
+// settle replaces the optimistic task with the server's version of it
+func (m *Model) settle(id string, confirmed *api.Task) tea.Cmd {
+	delete(m.pending, id)
+	if confirmed == nil {
+		return nil
+	}
+	m.tasks, _ = replaced(m.tasks, *confirmed)
+	return m.refreshItems()
+}
+
+// rollback restores the task as it was before a failed change
+func (m *Model) rollback(id string) tea.Cmd {
+	change, ok := m.pending[id]
+	if !ok {
+		return nil
+	}
+	delete(m.pending, id)
+
+	var found bool
+	m.tasks, found = replaced(m.tasks, change.before)
+	if !found {
+		m.tasks = append(m.tasks, change.before)
+	}
+	return m.refreshItems()
+}
+
+// replaced returns a copy of tasks with the task of the same ID swapped for
+// task, and whether there was one. The slice is copied because a snapshot
+// of the old one may still be being saved.
+func replaced(tasks []api.Task, task api.Task) ([]api.Task, bool) {
+	out := make([]api.Task, len(tasks), len(tasks)+1)
+	copy(out, tasks)
+
+	found := false
+	for i := range out {
+		if out[i].ID == task.ID {
+			out[i] = task
+			found = true
+		}
+	}
+	return out, found
+}
+
+// withPending returns tasks with the in-flight changes applied, so a
+// refresh that lands before the server has answered doesn't undo them
+func withPending(tasks []api.Task, pending map[string]pendingChange) []api.Task {
+	if len(pending) == 0 {
+		return tasks
+	}
+
+	shown := make([]api.Task, 0, len(tasks))
+	for _, task := range tasks {
+		change, ok := pending[task.ID]
+		switch {
+		case !ok:
+			shown = append(shown, task)
+		case change.after != nil:
+			shown = append(shown, *change.after)
+		}
+	}
+	return shown
+}
+
+// Commands
+
+// taskUpdatedMsg carries the server's copy of an updated task
+type taskUpdatedMsg struct {
+	task api.Task
+}
+
+type taskDeletedMsg struct {
+	id string
+}
+
+// actionFailedMsg reports a failed change, which is rolled back
+type actionFailedMsg struct {
+	taskID string
+	action string
+	err    error
+}
+
+// updateTask sends patch, which was made to before. Only the patched
+// fields are sent, and only if the task is still at before's version.
+func (m Model) updateTask(before api.Task, patch api.TaskPatch) tea.Cmd {
+	after := patch.Apply(before)
+	action := offline.Change{Kind: offline.ChangeUpdate, Task: after}.Describe()
+	return func() tea.Msg {
+		updated, err := m.client.PatchTask(before.ID, patch.IfMatch(before.ETag()))
+		if api.IsNotFound(err) {
+			return taskMissingMsg{id: before.ID, name: before.Name}
+		}
+		if api.IsConflict(err) {
+			current, err := m.client.GetTask(after.ID)
+			if err != nil {
+				return actionFailedMsg{taskID: before.ID, action: action, err: err}
+			}
+			return taskConflictMsg{before: before, mine: after, theirs: *current, patch: patch}
+		}
+		if common.Transient(err, m.client.ConnState()) {
+			return changeQueuedMsg{change: offlineUpdate(before, patch)}
+		}
+		if err != nil {
+			return actionFailedMsg{taskID: before.ID, action: action, err: err}
+		}
+		return taskUpdatedMsg{task: *updated}
+	}
+}
+
+func (m Model) deleteTask(task api.Task) tea.Cmd {
+	return func() tea.Msg {
+		err := m.client.DeleteTask(task.ID)
+		if api.IsNotFound(err) {
+			// Already gone, which is what was asked for
+			return taskDeletedMsg{id: task.ID}
+		}
+		if common.Transient(err, m.client.ConnState()) {
+			return changeQueuedMsg{change: offlineChange(offline.ChangeDelete, task)}
+		}
+		if err != nil {
+			return actionFailedMsg{taskID: task.ID, action: "delete " + task.Name, err: err}
+		}
+		return taskDeletedMsg{id: task.ID}
+	}
+}
+
+// failed rolls back a change the server refused and says so in a toast
+func (m *Model) failed(msg actionFailedMsg) tea.Cmd {
+	return tea.Batch(
+		m.rollback(msg.taskID),
+		m.toast.Show(fmt.Sprintf("Couldn't %s: %v", msg.action, msg.err), common.BadgeError),
+	)
+}
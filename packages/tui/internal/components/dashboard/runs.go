@@ -0,0 +1,396 @@
+// ABOUTME: Per-task run history opened from a dashboard row
+// ABOUTME: Lists a task's recent runs with each one's output, a diff against the run before, re-run and copy
+
+package dashboard
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/components/common"
+	"github.com/jem-computer/ritual/tui/internal/styles"
+	"github.com/jem-computer/ritual/tui/internal/theme"
+)
+
+const (
+	// runListRows is how many runs are listed at once above the output
+	runListRows = 5
+
+	// After a re-run is queued the logs are polled until it shows up, for
+	// at most runPollLimit polls
+	runPollInterval = 3 * time.Second
+	runPollLimit    = 40
+)
+
+// runsState is the run history screen for one task
+type runsState struct {
+	active   bool
+	task     api.Task
+	logs     []api.LogEntry // newest first
+	loading  bool
+	err      error
+	selected int  // index into logs
+	diff     bool // showing the changes since the run before rather than the output
+	scroll   int  // first body line shown
+
+	// The selected run's diff against the run before, kept up to date by
+	// refreshDiff while diff is on so View doesn't recompute it
+	diffLines      []diffLine
+	added, removed int
+
+	// Set while waiting for a queued re-run to finish; newest is the ID of
+	// the newest run when it was queued
+	waiting bool
+	newest  string
+	polls   int
+}
+
+// current returns the selected run
+func (r runsState) current() (api.LogEntry, bool) {
+	if r.selected < 0 || r.selected >= len(r.logs) {
+		return api.LogEntry{}, false
+	}
+	return r.logs[r.selected], true
+}
+
+// previous returns the run before the selected one
+func (r runsState) previous() (api.LogEntry, bool) {
+	if r.selected+1 >= len(r.logs) {
+		return api.LogEntry{}, false
+	}
+	return r.logs[r.selected+1], true
+}
+
+// refreshDiff recomputes the selected run's diff after the selection, the
+// diff toggle or the logs change
+func (r *runsState) refreshDiff() {
+	r.diffLines, r.added, r.removed = nil, 0, 0
+	if !r.diff {
+		return
+	}
+	run, ok := r.current()
+	if !ok {
+		return
+	}
+	previous, ok := r.previous()
+	if !ok {
+		return
+	}
+	r.diffLines = lineDiff(previous.Output, run.Output)
+	r.added, r.removed = changes(r.diffLines)
+}
+
+// openRuns shows the run history of task, starting from the logs already
+// fetched for its row while the latest ones load
+func (m *Model) openRuns(task api.Task) tea.Cmd {
+	m.runs = runsState{
+		active:  true,
+		task:    task,
+		logs:    m.logs[task.ID],
+		loading: true,
+	}
+	return m.loadRuns(task.ID)
+}
+
+// runsLoaded shows freshly fetched logs, keeping the same run selected
+func (m *Model) runsLoaded(msg runsLoadedMsg) tea.Cmd {
+	if !m.runs.active || msg.taskID != m.runs.task.ID {
+		return nil
+	}
+	m.runs.loading = false
+	m.runs.err = msg.err
+	if msg.err != nil {
+		// The cached runs, if any, stay on screen with the error above them
+		m.runs.waiting = false
+		return nil
+	}
+
+	selectedID := ""
+	if run, ok := m.runs.current(); ok {
+		selectedID = run.ID
+	}
+	m.runs.logs = msg.logs
+	m.logs[msg.taskID] = msg.logs
+	m.runs.selected = 0
+	for i, run := range msg.logs {
+		if run.ID == selectedID {
+			m.runs.selected = i
+		}
+	}
+	m.runs.refreshDiff()
+
+	if !m.runs.waiting {
+		return nil
+	}
+	if len(msg.logs) > 0 && msg.logs[0].ID != m.runs.newest {
+		m.runs.waiting = false
+		m.runs.selected, m.runs.scroll = 0, 0
+		m.runs.refreshDiff()
+		return m.toast.Show("The run finished", common.BadgeSuccess)
+	}
+	if m.runs.polls >= runPollLimit {
+		m.runs.waiting = false
+		return nil
+	}
+	return pollRuns(msg.taskID)
+}
+
+// runQueued starts waiting for a re-run to appear in the logs
+func (m *Model) runQueued(msg runQueuedMsg) tea.Cmd {
+	if msg.err != nil {
+		return m.toast.Show(fmt.Sprintf("Couldn't run %s: %v", msg.name, msg.err), common.BadgeError)
+	}
+	cmd := m.toast.Show("Queued a run of "+msg.name, common.BadgeInfo)
+	if !m.runs.active || msg.taskID != m.runs.task.ID {
+		return cmd
+	}
+
+	m.runs.waiting = true
+	m.runs.newest = ""
+	if len(m.runs.logs) > 0 {
+		m.runs.newest = m.runs.logs[0].ID
+	}
+	m.runs.polls = 0
+	return tea.Batch(cmd, pollRuns(msg.taskID))
+}
+
+// updateRuns handles keys while the run history is open
+func (m Model) updateRuns(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.runs = runsState{}
+		return m, nil
+
+	case "up", "k":
+		if m.runs.selected > 0 {
+			m.runs.selected--
+			m.runs.scroll = 0
+			m.runs.refreshDiff()
+		}
+
+	case "down", "j":
+		if m.runs.selected < len(m.runs.logs)-1 {
+			m.runs.selected++
+			m.runs.scroll = 0
+			m.runs.refreshDiff()
+		}
+
+	case "pgup":
+		m.runs.scroll = max(m.runs.scroll-m.runsBodyHeight(), 0)
+
+	case "pgdown":
+		m.runs.scroll = min(m.runs.scroll+m.runsBodyHeight(), max(len(m.runsBody())-m.runsBodyHeight(), 0))
+
+	case "v":
+		m.runs.diff = !m.runs.diff
+		m.runs.scroll = 0
+		m.runs.refreshDiff()
+
+	case "r":
+		if m.reconnecting {
+			return m, m.toast.Show("Read-only while offline: running needs the server", common.BadgeWarning)
+		}
+		return m, m.runTask(m.runs.task)
+
+	case "y":
+		run, ok := m.runs.current()
+		if !ok {
+			return m, nil
+		}
+		cmd := m.toast.Show("Copied the output of the run at "+run.ExecutedAt.Local().Format("Jan 2 15:04"), common.BadgeInfo)
+		return m, tea.Batch(tea.SetClipboard(run.Output), cmd)
+	}
+	return m, nil
+}
+
+// runsBodyHeight is how many lines of output or diff fit on screen
+func (m Model) runsBodyHeight() int {
+	rows := min(len(m.runs.logs), runListRows)
+	return max(m.height-10-rows, 3)
+}
+
+// runsBody returns every line of the selected run's output, or of its
+// diff against the run before, styled for display
+func (m Model) runsBody() []string {
+	t := theme.CurrentTheme()
+	mutedStyle := styles.NewStyle().Foreground(t.TextMuted())
+
+	run, ok := m.runs.current()
+	if !ok {
+		return nil
+	}
+
+	if !m.runs.diff {
+		var lines []string
+		if run.Error != "" {
+			lines = append(lines, styles.NewStyle().Foreground(t.Error()).Render("✗ "+run.Error))
+		}
+		if run.Output == "" {
+			return append(lines, mutedStyle.Render("No output"))
+		}
+		return append(lines, splitLines(run.Output)...)
+	}
+
+	if _, ok := m.runs.previous(); !ok {
+		return []string{mutedStyle.Render("This is the oldest run listed, so there's nothing to compare it with")}
+	}
+	diff := m.runs.diffLines
+	if m.runs.added == 0 && m.runs.removed == 0 {
+		return []string{mutedStyle.Render("Same output as the run before")}
+	}
+
+	addedStyle := styles.NewStyle().Foreground(t.Success())
+	removedStyle := styles.NewStyle().Foreground(t.Error())
+	lines := make([]string, len(diff))
+	for i, line := range diff {
+		switch line.op {
+		case diffAdded:
+			lines[i] = addedStyle.Render("+ " + line.text)
+		case diffRemoved:
+			lines[i] = removedStyle.Render("- " + line.text)
+		default:
+			lines[i] = mutedStyle.Render("  " + line.text)
+		}
+	}
+	return lines
+}
+
+// renderRuns lays out the run list above the selected run's output or diff
+func (m Model) renderRuns() string {
+	t := theme.CurrentTheme()
+	r := m.runs
+	width := m.width - 4
+
+	mutedStyle := styles.NewStyle().Foreground(t.TextMuted())
+	titleStyle := styles.NewStyle().Foreground(t.Primary()).Bold(true)
+
+	var s strings.Builder
+	s.WriteString(titleStyle.Render("◂ " + r.task.Name + " · run history"))
+	switch {
+	case r.waiting:
+		s.WriteString("  " + styles.NewStyle().Foreground(t.Warning()).Render("⧗ waiting for the run…"))
+	case r.loading:
+		s.WriteString("  " + mutedStyle.Render("⟳ loading…"))
+	}
+	s.WriteString("\n\n")
+
+	if r.err != nil {
+		s.WriteString(styles.NewStyle().Foreground(t.Error()).Render(fmt.Sprintf("Couldn't load runs: %v", r.err)))
+		s.WriteString("\n")
+	}
+	if len(r.logs) == 0 {
+		if !r.loading && r.err == nil {
+			s.WriteString(mutedStyle.Render("No runs yet. Press [r] to run it now"))
+			s.WriteString("\n")
+		}
+		s.WriteString("\n")
+		s.WriteString(mutedStyle.Render("r re-run • esc back"))
+		return s.String()
+	}
+
+	// A window of the run list that keeps the selected run in view
+	first := min(max(r.selected-runListRows/2, 0), max(len(r.logs)-runListRows, 0))
+	last := min(first+runListRows, len(r.logs))
+	for i := first; i < last; i++ {
+		s.WriteString(m.renderRunRow(r.logs[i], i == r.selected))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	heading := "Output"
+	if r.diff {
+		heading = "Changes since the run before"
+		if _, ok := r.previous(); ok {
+			heading += fmt.Sprintf("  +%d −%d", r.added, r.removed)
+		}
+	}
+	s.WriteString(titleStyle.Render(heading))
+	s.WriteString(mutedStyle.Render(fmt.Sprintf("  run %d of %d", r.selected+1, len(r.logs))))
+	s.WriteString("\n")
+
+	body := m.runsBody()
+	height := m.runsBodyHeight()
+	scroll := min(r.scroll, max(len(body)-height, 0))
+	shown := body[scroll:min(scroll+height, len(body))]
+	for _, line := range shown {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		s.WriteString(ansi.Truncate(line, width, "…"))
+		s.WriteString("\n")
+	}
+	if more := len(body) - scroll - len(shown); more > 0 {
+		s.WriteString(mutedStyle.Render(fmt.Sprintf("… %d more lines (pgdn)", more)))
+		s.WriteString("\n")
+	}
+
+	view := "diff"
+	if r.diff {
+		view = "output"
+	}
+	s.WriteString("\n")
+	s.WriteString(mutedStyle.Render(fmt.Sprintf("↑/↓ run • pgup/pgdn scroll • v %s • r re-run • y copy output • esc back", view)))
+	return s.String()
+}
+
+// renderRunRow renders one run in the list: outcome, time and duration
+func (m Model) renderRunRow(run api.LogEntry, selected bool) string {
+	t := theme.CurrentTheme()
+
+	outcome := styles.NewStyle().Foreground(t.Success()).Render("✓")
+	if run.Status != "SUCCESS" {
+		outcome = styles.NewStyle().Foreground(t.Error()).Render("✗")
+	}
+
+	when := fmt.Sprintf("%s  %-10s  %s",
+		run.ExecutedAt.Local().Format("Mon Jan _2 15:04"),
+		common.RelativeTime(run.ExecutedAt, m.now),
+		formatDuration(time.Duration(run.Duration)*time.Millisecond))
+
+	if selected {
+		return styles.NewStyle().Foreground(t.Primary()).Bold(true).Render("✦ ") + outcome + " " +
+			styles.NewStyle().Foreground(t.Text()).Bold(true).Render(when)
+	}
+	return "  " + outcome + " " + styles.NewStyle().Foreground(t.TextMuted()).Render(when)
+}
+
+// Commands
+
+type runsLoadedMsg struct {
+	taskID string
+	logs   []api.LogEntry
+	err    error
+}
+
+type runQueuedMsg struct {
+	taskID string
+	name   string
+	err    error
+}
+
+// runsPollMsg asks for the logs again while a re-run is awaited
+type runsPollMsg struct {
+	taskID string
+}
+
+func (m Model) loadRuns(taskID string) tea.Cmd {
+	return func() tea.Msg {
+		logs, err := m.client.GetTaskLogs(taskID)
+		return runsLoadedMsg{taskID: taskID, logs: logs, err: err}
+	}
+}
+
+func (m Model) runTask(task api.Task) tea.Cmd {
+	return func() tea.Msg {
+		err := m.client.RunTask(task.ID)
+		return runQueuedMsg{taskID: task.ID, name: task.Name, err: err}
+	}
+}
+
+func pollRuns(taskID string) tea.Cmd {
+	return tea.Tick(runPollInterval, func(time.Time) tea.Msg {
+		return runsPollMsg{taskID: taskID}
+	})
+}
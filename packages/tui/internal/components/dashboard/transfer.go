@@ -0,0 +1,203 @@
+// ABOUTME: Dashboard export and import of rituals to and from YAML/JSON files
+// ABOUTME: Prompts for a path and asks how to handle duplicate names on import
+
+package dashboard
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/textinput"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/ritualfile"
+	"github.com/jem-computer/ritual/tui/internal/styles"
+	"github.com/jem-computer/ritual/tui/internal/theme"
+)
+
+// defaultTransferPath is offered when exporting or importing
+const defaultTransferPath = "rituals.yaml"
+
+// transferMode is the step of an in-progress export or import
+type transferMode int
+
+const (
+	transferNone transferMode = iota
+	transferExportPath
+	transferImportPath
+	transferImportDuplicates
+)
+
+// transferState holds an in-progress export or import
+type transferState struct {
+	mode      transferMode
+	pathInput textinput.Model
+
+	// Set while asking how to handle duplicates
+	doc        ritualfile.Document
+	duplicates []ritualfile.Ritual
+}
+
+func newPathInput() textinput.Model {
+	input := textinput.New()
+	input.Placeholder = defaultTransferPath
+	input.CharLimit = 500
+	input.SetValue(defaultTransferPath)
+	input.CursorEnd()
+	return input
+}
+
+// startTransfer opens the path prompt for an export or import
+func (m *Model) startTransfer(mode transferMode) tea.Cmd {
+	m.transfer = transferState{mode: mode, pathInput: newPathInput()}
+	return m.transfer.pathInput.Focus()
+}
+
+// Editing reports whether the dashboard is taking text input or has a
+// screen open that uses letter keys, so the parent shouldn't treat them
+// as shortcuts
+func (m Model) Editing() bool {
+	return m.transfer.mode != transferNone || m.conflict.active || m.runs.active
+}
+
+// updateTransfer handles keys while an export or import is in progress
+func (m Model) updateTransfer(msg tea.KeyMsg) (Model, tea.Cmd) {
+	if msg.String() == "esc" {
+		m.transfer = transferState{}
+		return m, m.list.NewStatusMessage("Cancelled")
+	}
+
+	switch m.transfer.mode {
+	case transferExportPath, transferImportPath:
+		if msg.String() != "enter" {
+			var cmd tea.Cmd
+			m.transfer.pathInput, cmd = m.transfer.pathInput.Update(msg)
+			return m, cmd
+		}
+
+		path := strings.TrimSpace(m.transfer.pathInput.Value())
+		if path == "" {
+			return m, nil
+		}
+		mode := m.transfer.mode
+		m.transfer = transferState{}
+		if mode == transferExportPath {
+			return m, exportTasks(path, m.tasks)
+		}
+		return m, readImport(path, m.tasks)
+
+	case transferImportDuplicates:
+		strategy, ok := map[string]ritualfile.Strategy{
+			"s": ritualfile.Skip,
+			"o": ritualfile.Overwrite,
+			"r": ritualfile.Rename,
+		}[msg.String()]
+		if !ok {
+			return m, nil
+		}
+		doc := m.transfer.doc
+		m.transfer = transferState{}
+		return m, m.importTasks(doc, strategy)
+	}
+
+	return m, nil
+}
+
+// renderTransfer renders the prompt for the current export/import step
+func (m Model) renderTransfer() string {
+	t := theme.CurrentTheme()
+
+	labelStyle := styles.NewStyle().Foreground(t.Primary()).Bold(true)
+	mutedStyle := styles.NewStyle().Foreground(t.TextMuted())
+
+	switch m.transfer.mode {
+	case transferExportPath:
+		return labelStyle.Render(fmt.Sprintf("Export %d rituals to ", len(m.tasks))) +
+			m.transfer.pathInput.View() + "\n" +
+			mutedStyle.Render("enter to write (.json for JSON, otherwise YAML) • esc to cancel")
+
+	case transferImportPath:
+		return labelStyle.Render("Import rituals from ") +
+			m.transfer.pathInput.View() + "\n" +
+			mutedStyle.Render("enter to read • esc to cancel")
+
+	case transferImportDuplicates:
+		names := make([]string, len(m.transfer.duplicates))
+		for i, r := range m.transfer.duplicates {
+			names[i] = fmt.Sprintf("%q", r.Name)
+		}
+		return styles.NewStyle().Foreground(t.Warning()).Bold(true).
+			Render(fmt.Sprintf("%d of %d rituals already exist: %s",
+				len(m.transfer.duplicates), len(m.transfer.doc.Rituals), strings.Join(names, ", "))) + "\n" +
+			mutedStyle.Render("[s]kip them • [o]verwrite them • [r]ename the imports • esc to cancel")
+	}
+
+	return ""
+}
+
+// Commands
+
+type transferDoneMsg struct {
+	message string
+	reload  bool
+}
+
+type importReadMsg struct {
+	doc        ritualfile.Document
+	duplicates []ritualfile.Ritual
+}
+
+// exportTasks writes the tasks to path, choosing the format by extension
+func exportTasks(path string, tasks []api.Task) tea.Cmd {
+	return func() tea.Msg {
+		format := ritualfile.YAML
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			format = ritualfile.JSON
+		}
+
+		f, err := os.Create(path)
+		if err != nil {
+			return transferDoneMsg{message: fmt.Sprintf("Export failed: %v", err)}
+		}
+
+		err = ritualfile.Encode(f, ritualfile.FromTasks(tasks), format)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return transferDoneMsg{message: fmt.Sprintf("Export failed: %v", err)}
+		}
+
+		return transferDoneMsg{message: fmt.Sprintf("Exported %d rituals to %s", len(tasks), path)}
+	}
+}
+
+// readImport reads and validates the document before anything is created,
+// so duplicate handling can be decided up front
+func readImport(path string, tasks []api.Task) tea.Cmd {
+	return func() tea.Msg {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return transferDoneMsg{message: fmt.Sprintf("Import failed: %v", err)}
+		}
+
+		doc, err := ritualfile.Decode(data)
+		if err != nil {
+			return transferDoneMsg{message: fmt.Sprintf("Import failed: %s: %v", path, err)}
+		}
+
+		return importReadMsg{doc: doc, duplicates: ritualfile.Duplicates(doc, tasks)}
+	}
+}
+
+func (m Model) importTasks(doc ritualfile.Document, strategy ritualfile.Strategy) tea.Cmd {
+	return func() tea.Msg {
+		result, err := ritualfile.Import(m.client, doc, ritualfile.Always(strategy))
+		if err != nil {
+			return transferDoneMsg{message: fmt.Sprintf("Import stopped (%s): %v", result, err), reload: true}
+		}
+		return transferDoneMsg{message: "Imported: " + result.String(), reload: true}
+	}
+}
@@ -0,0 +1,279 @@
+// ABOUTME: User configuration loaded from ~/.config/ritual/config.yaml
+// ABOUTME: Holds named server profiles with their credentials and TLS settings
+
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultServer is used when neither a profile nor a flag names a server
+// and no local server is listening on SocketPath
+const DefaultServer = "http://localhost:8080"
+
+// Config is the contents of config.yaml
+type Config struct {
+	// DefaultProfile is used when no profile is chosen explicitly
+	DefaultProfile string             `yaml:"default_profile,omitempty"`
+	Profiles       map[string]Profile `yaml:"profiles,omitempty"`
+}
+
+// Profile describes how to reach one Ritual server
+type Profile struct {
+	Server string `yaml:"server"`
+
+	// Accent colors the profile's label in the tab bar, e.g. "#f5a97f", so
+	// it's obvious which server is in use
+	Accent string `yaml:"accent,omitempty"`
+
+	// The bearer token is read from the first of these that is set
+	Token     string `yaml:"token,omitempty"`
+	TokenEnv  string `yaml:"token_env,omitempty"`  // name of an environment variable
+	TokenFile string `yaml:"token_file,omitempty"` // file holding only the token
+
+	TLS TLS `yaml:"tls,omitempty"`
+}
+
+// TLS configures HTTPS for a profile. CA verifies the server; Cert and Key
+// are the client certificate for servers that require mutual TLS.
+type TLS struct {
+	CA         string `yaml:"ca,omitempty"`
+	Cert       string `yaml:"cert,omitempty"`
+	Key        string `yaml:"key,omitempty"`
+	ServerName string `yaml:"server_name,omitempty"` // overrides the name checked in the server's certificate
+}
+
+// Dir returns the ritual config directory, honouring $XDG_CONFIG_HOME
+func Dir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "ritual"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "ritual"), nil
+}
+
+// CacheDir returns the ritual cache directory, honouring $XDG_CACHE_HOME.
+// Everything in it can be rebuilt from the server.
+func CacheDir() (string, error) {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "ritual"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".cache", "ritual"), nil
+}
+
+// SocketPath returns where a server started for this user listens instead
+// of a TCP port, or "" when $XDG_RUNTIME_DIR isn't set. The runtime dir is
+// private to the user and cleared on logout.
+func SocketPath() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "ritual", "ritual.sock")
+}
+
+// localServer returns the local server's socket URL when one is listening,
+// otherwise DefaultServer
+func localServer() string {
+	path := SocketPath()
+	if path == "" {
+		return DefaultServer
+	}
+	if info, err := os.Stat(path); err == nil && info.Mode().Type() == fs.ModeSocket {
+		return api.SocketURL(path)
+	}
+	return DefaultServer
+}
+
+// Path returns the location of config.yaml
+func Path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// Load reads config.yaml. A missing file is an empty config, not an error.
+func Load() (Config, error) {
+	var cfg Config
+
+	path, err := Path()
+	if err != nil {
+		return cfg, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if cfg.DefaultProfile != "" {
+		if _, ok := cfg.Profiles[cfg.DefaultProfile]; !ok {
+			return cfg, fmt.Errorf("%s: default_profile %q is not defined", path, cfg.DefaultProfile)
+		}
+	}
+	return cfg, nil
+}
+
+// Profile returns the named profile, or the default one when name is empty.
+// Without a config file the default profile is the local server, reached
+// through its socket when it has one, with its token read from
+// $RITUAL_TOKEN, which is handy in CI.
+func (c Config) Profile(name string) (string, Profile, error) {
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" {
+		return "", Profile{Server: localServer(), TokenEnv: "RITUAL_TOKEN"}, nil
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return "", Profile{}, fmt.Errorf("unknown profile %q (known: %s)", name, strings.Join(c.Names(), ", "))
+	}
+	if profile.Server == "" {
+		profile.Server = DefaultServer
+	}
+	return name, profile, nil
+}
+
+// Names returns the profile names in alphabetical order
+func (c Config) Names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveToken returns the profile's bearer token, or "" when it has none
+func (p Profile) ResolveToken() (string, error) {
+	switch {
+	case p.TokenEnv != "":
+		return strings.TrimSpace(os.Getenv(p.TokenEnv)), nil
+	case p.TokenFile != "":
+		data, err := os.ReadFile(expandHome(p.TokenFile))
+		if errors.Is(err, fs.ErrNotExist) {
+			// Not logged in yet; the TUI offers to write it
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return p.Token, nil
+}
+
+// SaveToken stores token in the profile's token_file, creating it readable
+// only by the user. It reports false when the profile has no token file,
+// in which case the token can't be persisted.
+func (p Profile) SaveToken(token string) (bool, error) {
+	if p.TokenFile == "" {
+		return false, nil
+	}
+
+	path := expandHome(p.TokenFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(path, []byte(token+"\n"), 0o600)
+}
+
+// TLSConfig builds the client TLS configuration, or nil when the profile
+// uses the system defaults
+func (p Profile) TLSConfig() (*tls.Config, error) {
+	t := p.TLS
+	if t == (TLS{}) {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{ServerName: t.ServerName, MinVersion: tls.VersionTLS12}
+
+	if t.CA != "" {
+		pem, err := os.ReadFile(expandHome(t.CA))
+		if err != nil {
+			return nil, fmt.Errorf("tls ca: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls ca: no certificates in %s", t.CA)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if (t.Cert == "") != (t.Key == "") {
+		return nil, errors.New("tls: cert and key must be set together")
+	}
+	if t.Cert != "" {
+		cert, err := tls.LoadX509KeyPair(expandHome(t.Cert), expandHome(t.Key))
+		if err != nil {
+			return nil, fmt.Errorf("tls client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// Client returns an API client for the profile with its token and TLS
+// settings applied
+func (p Profile) Client() (*api.Client, error) {
+	client := api.NewClient(p.Server)
+
+	tlsConfig, err := p.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		client = client.WithTLS(tlsConfig)
+	}
+
+	token, err := p.ResolveToken()
+	if err != nil {
+		return nil, fmt.Errorf("token: %w", err)
+	}
+	client.SetToken(token)
+	return client, nil
+}
+
+// expandHome replaces a leading ~/ with the user's home directory
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
@@ -0,0 +1,98 @@
+// ABOUTME: Local snapshot of a server's tasks and run logs for working offline
+// ABOUTME: Persists the last synced state plus changes queued while the server was unreachable
+
+package offline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/config"
+)
+
+// Snapshot is the last known state of one server
+type Snapshot struct {
+	Server   string                    `json:"server"`
+	SyncedAt time.Time                 `json:"syncedAt"` // when Tasks was fetched
+	Tasks    []api.Task                `json:"tasks"`
+	Logs     map[string][]api.LogEntry `json:"logs,omitempty"`  // recent executions by task ID, newest first
+	Queue    []Change                  `json:"queue,omitempty"` // oldest first
+}
+
+// Path returns the snapshot file for server under the cache directory, one
+// file per server so switching profiles never shows another server's tasks
+func Path(server string) (string, error) {
+	dir, err := config.CacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	h := fnv.New64a()
+	io.WriteString(h, server)
+	return filepath.Join(dir, fmt.Sprintf("offline-%x.json", h.Sum64())), nil
+}
+
+// Load reads the snapshot for server. A missing file is an empty snapshot,
+// not an error.
+func Load(server string) (Snapshot, error) {
+	snap := Snapshot{Server: server}
+
+	path, err := Path(server)
+	if err != nil {
+		return snap, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return snap, nil
+	}
+	if err != nil {
+		return snap, err
+	}
+
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return Snapshot{Server: server}, fmt.Errorf("%s: %w", path, err)
+	}
+	return snap, nil
+}
+
+// Save writes the snapshot, readable only by the user since prompts and
+// outputs may be private. The file is replaced atomically so a crash never
+// leaves half a snapshot behind.
+func (s Snapshot) Save() error {
+	path, err := Path(s.Server)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".offline-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
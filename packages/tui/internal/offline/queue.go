@@ -0,0 +1,158 @@
+// ABOUTME: Changes made while the server was unreachable, replayed once it's back
+// ABOUTME: A change is dropped as a conflict when the task changed on the server in the meantime
+
+package offline
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jem-computer/ritual/tui/internal/api"
+)
+
+// ChangeKind is what an offline change does to its task
+type ChangeKind string
+
+const (
+	ChangeUpdate ChangeKind = "update"
+	ChangeDelete ChangeKind = "delete"
+)
+
+// Change is a task edit made offline
+type Change struct {
+	Kind ChangeKind `json:"kind"`
+	// Task is the task as changed offline; for deletes, as last seen
+	Task api.Task `json:"task"`
+	// Patch is the fields an update changed, sent on replay so edits made
+	// on the server to other fields aren't overwritten
+	Patch api.TaskPatch `json:"patch"`
+	// Base is the task as it was when first changed offline. If one of its
+	// user-editable fields differs on the server, someone else changed it
+	// since; a task that only ran in the meantime is no conflict.
+	Base     api.Task  `json:"base"`
+	QueuedAt time.Time `json:"queuedAt"`
+}
+
+// Describe names the change for status messages, e.g. "pause Daily digest"
+func (c Change) Describe() string {
+	switch {
+	case c.Kind == ChangeDelete:
+		return "delete " + c.Task.Name
+	case c.Task.Status == "PAUSED":
+		return "pause " + c.Task.Name
+	case c.Task.Status == "ACTIVE":
+		return "resume " + c.Task.Name
+	}
+	return "update " + c.Task.Name
+}
+
+// Enqueue adds change to queue. A task has at most one queued change: a
+// later one replaces it but keeps the original Base, so conflicts are
+// judged against the state the user first saw. Two updates combine their
+// patches.
+func Enqueue(queue []Change, change Change) []Change {
+	// Copy rather than edit in place; a snapshot may be saving the old queue
+	queue = append([]Change(nil), queue...)
+	for i, queued := range queue {
+		if queued.Task.ID == change.Task.ID {
+			change.Base = queued.Base
+			if queued.Kind == ChangeUpdate && change.Kind == ChangeUpdate {
+				change.Patch = queued.Patch.Merge(change.Patch)
+			}
+			queue[i] = change
+			return queue
+		}
+	}
+	return append(queue, change)
+}
+
+// Apply returns tasks as they'll be once queue is replayed: updated tasks
+// replaced and deleted ones removed
+func Apply(tasks []api.Task, queue []Change) []api.Task {
+	applied := make([]api.Task, 0, len(tasks))
+	for _, task := range tasks {
+		change, ok := Pending(queue, task.ID)
+		switch {
+		case !ok:
+			applied = append(applied, task)
+		case change.Kind == ChangeUpdate:
+			applied = append(applied, change.Task)
+		}
+	}
+	return applied
+}
+
+// Pending returns the queued change for a task, if any
+func Pending(queue []Change, taskID string) (Change, bool) {
+	for _, change := range queue {
+		if change.Task.ID == taskID {
+			return change, true
+		}
+	}
+	return Change{}, false
+}
+
+// Outcome is the result of replaying one change
+type Outcome struct {
+	Change Change
+	// Conflict explains why the change was dropped, when the task changed
+	// or disappeared on the server while offline
+	Conflict string
+	Err      error
+}
+
+// Replay applies queued changes in order. It stops at the first change
+// that couldn't reach the server and returns it with the rest as
+// remaining, to be retried on the next reconnect. Conflicting and failed
+// changes are reported and not retried.
+func Replay(ctx context.Context, client *api.Client, queue []Change) (outcomes []Outcome, remaining []Change) {
+	for i, change := range queue {
+		outcome, retry := replay(ctx, client, change)
+		if retry {
+			return outcomes, queue[i:]
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	return outcomes, nil
+}
+
+// replay applies one change, reporting retry when the server couldn't be
+// reached
+func replay(ctx context.Context, client *api.Client, change Change) (Outcome, bool) {
+	outcome := Outcome{Change: change}
+
+	current, err := client.GetTaskContext(ctx, change.Task.ID)
+	switch {
+	case api.IsNotFound(err) && change.Kind == ChangeDelete:
+		// Deleted on the server too; nothing left to do
+		return outcome, false
+	case api.IsNotFound(err):
+		outcome.Conflict = fmt.Sprintf("%s was deleted on the server", change.Task.Name)
+		return outcome, false
+	case err != nil:
+		outcome.Err = err
+		return outcome, api.StatusCode(err) == 0
+	case api.Edited(*current, change.Base):
+		outcome.Conflict = fmt.Sprintf("%s changed on the server while offline", change.Task.Name)
+		return outcome, false
+	}
+
+	switch change.Kind {
+	case ChangeDelete:
+		err = client.DeleteTaskContext(ctx, change.Task.ID)
+		if api.IsNotFound(err) {
+			err = nil
+		}
+	default:
+		// Conditional on the version just checked, in case the task changes
+		// in between
+		_, err = client.PatchTaskContext(ctx, change.Task.ID, change.Patch.IfMatch(current.ETag()))
+		if api.IsConflict(err) {
+			outcome.Conflict = fmt.Sprintf("%s changed on the server while offline", change.Task.Name)
+			return outcome, false
+		}
+	}
+	outcome.Err = err
+	return outcome, err != nil && api.StatusCode(err) == 0
+}
@@ -0,0 +1,54 @@
+// ABOUTME: Tests for replaying changes queued offline against the in-memory server
+// ABOUTME: Covers telling edits made elsewhere from a task that merely ran
+
+package offline_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/offline"
+	"github.com/jem-computer/ritual/tui/internal/testutil"
+)
+
+func TestReplayAfterTaskRan(t *testing.T) {
+	srv := testutil.NewServer(t)
+	task := srv.AddTask(api.Task{Name: "Digest", Status: "ACTIVE"})
+	client := srv.Client()
+
+	patch := api.Patch().Status("PAUSED")
+	queue := []offline.Change{{Kind: offline.ChangeUpdate, Task: patch.Apply(task), Patch: patch, Base: task}}
+
+	// The task runs while the client is offline
+	srv.RecordRun(task.ID, time.Now())
+
+	outcomes, remaining := offline.Replay(context.Background(), client, queue)
+	if len(remaining) != 0 || len(outcomes) != 1 || outcomes[0].Conflict != "" || outcomes[0].Err != nil {
+		t.Fatalf("got %+v, remaining %d; want the pause replayed", outcomes, len(remaining))
+	}
+	if current, _ := srv.Task(task.ID); current.Status != "PAUSED" {
+		t.Errorf("status is %s after replay, want PAUSED", current.Status)
+	}
+}
+
+func TestReplayAfterEditElsewhere(t *testing.T) {
+	srv := testutil.NewServer(t)
+	task := srv.AddTask(api.Task{Name: "Digest", Status: "ACTIVE"})
+	client := srv.Client()
+
+	queue := []offline.Change{{Kind: offline.ChangeDelete, Task: task, Base: task}}
+
+	if _, err := client.PatchTask(task.ID, api.Patch().Prompt("Summarise the week")); err != nil {
+		t.Fatal(err)
+	}
+
+	outcomes, _ := offline.Replay(context.Background(), client, queue)
+	if len(outcomes) != 1 || outcomes[0].Conflict == "" {
+		t.Fatalf("got %+v, want the delete dropped as a conflict", outcomes)
+	}
+	if _, ok := srv.Task(task.ID); !ok {
+		t.Error("task was deleted despite the conflicting edit")
+	}
+}
@@ -0,0 +1,123 @@
+// ABOUTME: Prompt template expansion mirroring the server's prompt-template.ts
+// ABOUTME: Substitutes {{date}}, {{env}}, {{var}} and {{last_output}} directives per run
+
+package prompt
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// defaultDateLayout is used by a bare {{date}}
+const defaultDateLayout = "2006-01-02"
+
+// EnvPrefix is prepended to the name in {{env "NAME"}}: the server only
+// exposes its variables named RITUAL_VAR_*, never its secrets
+const EnvPrefix = "RITUAL_VAR_"
+
+// directivePattern matches {{name}} and {{name "argument"}}
+var directivePattern = regexp.MustCompile(`\{\{\s*([a-z_]+)(?:\s+"([^"]*)")?\s*\}\}`)
+
+// Context holds the values available to a single run
+type Context struct {
+	// RunAt is the fire time the prompt is rendered for
+	RunAt time.Time
+	// Variables are the task's user-defined values for {{var "name"}}
+	Variables map[string]string
+	// LastOutput is the output of the task's previous successful run
+	LastOutput string
+	// Env looks up {{env "NAME"}} by its full variable name, EnvPrefix
+	// included. The values live in the server's environment, so by default
+	// the variable's name stands in for its value.
+	Env func(string) string
+}
+
+// HasDirectives reports whether the prompt uses any template syntax
+func HasDirectives(tmpl string) bool {
+	return directivePattern.MatchString(tmpl)
+}
+
+// Variables returns the names referenced by {{var "name"}}, in order of
+// first appearance
+func Variables(tmpl string) []string {
+	var names []string
+	seen := make(map[string]bool)
+
+	for _, m := range directivePattern.FindAllStringSubmatch(tmpl, -1) {
+		if m[1] == "var" && m[2] != "" && !seen[m[2]] {
+			seen[m[2]] = true
+			names = append(names, m[2])
+		}
+	}
+	return names
+}
+
+// Render expands every directive in tmpl. Unknown directives and missing
+// arguments are reported as errors rather than passed through to the model.
+func Render(tmpl string, ctx Context) (string, error) {
+	env := ctx.Env
+	if env == nil {
+		env = func(name string) string { return "‹" + name + " on the server›" }
+	}
+
+	var firstErr error
+	out := directivePattern.ReplaceAllStringFunc(tmpl, func(match string) string {
+		m := directivePattern.FindStringSubmatch(match)
+		name, arg := m[1], m[2]
+
+		switch name {
+		case "date":
+			layout := arg
+			if layout == "" {
+				layout = defaultDateLayout
+			}
+			return ctx.RunAt.Format(layout)
+
+		case "env":
+			if arg == "" && firstErr == nil {
+				firstErr = fmt.Errorf(`{{env}} needs a variable name, e.g. {{env "TEAM"}}`)
+			}
+			return env(EnvPrefix + arg)
+
+		case "var":
+			value, ok := ctx.Variables[arg]
+			if !ok && firstErr == nil {
+				firstErr = fmt.Errorf("variable %q has no value", arg)
+			}
+			return value
+
+		case "last_output":
+			return ctx.LastOutput
+
+		default:
+			if firstErr == nil {
+				firstErr = fmt.Errorf("unknown template function %q", name)
+			}
+			return match
+		}
+	})
+
+	return out, firstErr
+}
+
+// Validate checks that every directive in tmpl is known and well formed
+func Validate(tmpl string) error {
+	if strings.Count(tmpl, "{{") != len(directivePattern.FindAllStringIndex(tmpl, -1)) {
+		return fmt.Errorf(`malformed template directive; use e.g. {{date "Monday"}} or {{var "repo"}}`)
+	}
+
+	for _, m := range directivePattern.FindAllStringSubmatch(tmpl, -1) {
+		switch m[1] {
+		case "date", "last_output":
+		case "env", "var":
+			if m[2] == "" {
+				return fmt.Errorf(`{{%s}} needs a name, e.g. {{%s "name"}}`, m[1], m[1])
+			}
+		default:
+			return fmt.Errorf("unknown template function %q", m[1])
+		}
+	}
+	return nil
+}
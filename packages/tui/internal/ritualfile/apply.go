@@ -0,0 +1,313 @@
+// ABOUTME: Declarative sync of ritual definition files against the server
+// ABOUTME: Loads a directory of definitions, plans creates/updates/deletes and applies them
+
+package ritualfile
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"gopkg.in/yaml.v3"
+)
+
+// Definition is a ritual read from a definition file
+type Definition struct {
+	Ritual
+	// Source is the file the ritual was defined in, recorded on the task
+	// as its owner
+	Source string
+}
+
+// singleRitual is the one-ritual-per-file form of a definition
+type singleRitual struct {
+	Version int `yaml:"version"`
+	Ritual  `yaml:",inline"`
+}
+
+// LoadDefinitions reads every .yaml/.yml/.json file under path (or path
+// itself when it is a file). A file holds either a full document with a
+// rituals list or a single ritual with a version. Sources are recorded
+// relative to base so they stay stable across checkouts.
+func LoadDefinitions(path, base string) ([]Definition, error) {
+	var files []string
+
+	err := filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		switch filepath.Ext(p) {
+		case ".yaml", ".yml", ".json":
+			if !d.IsDir() {
+				files = append(files, p)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var defs []Definition
+	defined := make(map[string]string)
+
+	for _, file := range files {
+		source := SourcePath(file, base)
+
+		rituals, err := decodeDefinitionFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+
+		for _, r := range rituals {
+			if other, ok := defined[r.Name]; ok {
+				return nil, fmt.Errorf("%s: ritual %q is already defined in %s", source, r.Name, other)
+			}
+			defined[r.Name] = source
+			defs = append(defs, Definition{Ritual: r, Source: source})
+		}
+	}
+
+	return defs, nil
+}
+
+// SourcePath returns path relative to base in the form recorded as a
+// task's owner, or path itself when it can't be made relative
+func SourcePath(path, base string) string {
+	if rel, err := filepath.Rel(base, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+func decodeDefinitionFile(file string) ([]Ritual, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var probe map[string]any
+	if err := yaml.Unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+	if _, ok := probe["rituals"]; ok {
+		doc, err := Decode(data)
+		return doc.Rituals, err
+	}
+
+	var single singleRitual
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&single); err != nil {
+		return nil, err
+	}
+
+	// Reuse the document checks for version and field validation
+	doc := Document{Version: single.Version, Rituals: []Ritual{single.Ritual}}
+	if err := doc.validate(); err != nil {
+		return nil, err
+	}
+	return doc.Rituals, nil
+}
+
+// Action is what applying a plan does to one ritual
+type Action string
+
+const (
+	ActionCreate    Action = "create"
+	ActionUpdate    Action = "update"
+	ActionDelete    Action = "delete"
+	ActionUnchanged Action = "unchanged"
+	// ActionOrphaned is a managed task whose definition was removed; it is
+	// only deleted with prune
+	ActionOrphaned Action = "orphaned"
+)
+
+// Change is one step of a plan
+type Change struct {
+	Action Action
+	Name   string
+	Source string
+	// Fields lists what an update changes
+	Fields []string
+
+	desired  api.Task
+	existing *api.Task
+}
+
+// Plan is the ordered set of changes needed to match the definitions
+type Plan []Change
+
+// Counts tallies the plan's changes by action
+func (p Plan) Counts() map[Action]int {
+	counts := make(map[Action]int)
+	for _, c := range p {
+		counts[c.Action]++
+	}
+	return counts
+}
+
+// HasChanges reports whether applying the plan would touch the server
+func (p Plan) HasChanges() bool {
+	for _, c := range p {
+		if c.Action == ActionCreate || c.Action == ActionUpdate || c.Action == ActionDelete {
+			return true
+		}
+	}
+	return false
+}
+
+// PlanApply diffs definitions against the server's tasks, matching by name.
+// Existing tasks with a defined name are adopted and become managed. With
+// prune, managed tasks whose definition is gone are deleted, but only those
+// managed from scope, the applied path as given by SourcePath; tasks managed
+// from other paths or never managed are left alone either way.
+func PlanApply(defs []Definition, tasks []api.Task, scope string, prune bool) Plan {
+	byName := indexByName(tasks)
+	defined := make(map[string]bool, len(defs))
+
+	var plan Plan
+	for _, def := range defs {
+		defined[def.Name] = true
+
+		existing, ok := byName[def.Name]
+		if !ok {
+			desired := def.Task()
+			desired.ManagedBy = def.Source
+			plan = append(plan, Change{Action: ActionCreate, Name: def.Name, Source: def.Source, desired: desired})
+			continue
+		}
+
+		desired, fields := merge(existing, def)
+		action := ActionUpdate
+		if len(fields) == 0 {
+			action = ActionUnchanged
+		}
+		plan = append(plan, Change{
+			Action:   action,
+			Name:     def.Name,
+			Source:   def.Source,
+			Fields:   fields,
+			desired:  desired,
+			existing: &existing,
+		})
+	}
+
+	for _, task := range tasks {
+		if task.ManagedBy == "" || defined[task.Name] || !within(task.ManagedBy, scope) {
+			continue
+		}
+		action := ActionOrphaned
+		if prune {
+			action = ActionDelete
+		}
+		existing := task
+		plan = append(plan, Change{Action: action, Name: task.Name, Source: task.ManagedBy, existing: &existing})
+	}
+
+	return plan
+}
+
+// within reports whether source is scope itself or a file under it
+func within(source, scope string) bool {
+	if scope == "." {
+		return !strings.HasPrefix(source, "../") && !path.IsAbs(source)
+	}
+	return source == scope || strings.HasPrefix(source, strings.TrimSuffix(scope, "/")+"/")
+}
+
+// merge applies a definition to an existing task, returning the updated
+// task and the names of the fields that changed. An omitted status leaves
+// the task's current status alone so pausing from the TUI sticks.
+func merge(existing api.Task, def Definition) (api.Task, []string) {
+	updated := existing
+	var fields []string
+
+	set := func(name string, dst *string, value string) {
+		if *dst != value {
+			*dst = value
+			fields = append(fields, name)
+		}
+	}
+
+	set("prompt", &updated.Prompt, def.Prompt)
+	set("schedule", &updated.Schedule, def.Schedule)
+	set("model", &updated.Model, def.Model)
+	set("output", &updated.Output, def.Output)
+	if def.Status != "" {
+		set("status", &updated.Status, def.Status)
+	}
+	set("managedBy", &updated.ManagedBy, def.Source)
+
+	if !maps.Equal(existing.Variables, def.Variables) && (len(existing.Variables) > 0 || len(def.Variables) > 0) {
+		updated.Variables = maps.Clone(def.Variables)
+		if updated.Variables == nil {
+			// Sent as {} to clear them; a nil map would be left out of the
+			// request and the server would keep the old ones
+			updated.Variables = map[string]string{}
+		}
+		fields = append(fields, "variables")
+	}
+
+	return updated, fields
+}
+
+// patchFields builds a patch setting the named fields of task, so the
+// server's bookkeeping, like LastRun, and fields the plan didn't change are
+// left as they are
+func patchFields(task api.Task, fields []string) api.TaskPatch {
+	patch := api.Patch()
+	for _, field := range fields {
+		switch field {
+		case "name":
+			patch = patch.Name(task.Name)
+		case "prompt":
+			patch = patch.Prompt(task.Prompt)
+		case "schedule":
+			patch = patch.Schedule(task.Schedule)
+		case "model":
+			patch = patch.Model(task.Model)
+		case "output":
+			patch = patch.Output(task.Output)
+		case "status":
+			patch = patch.Status(task.Status)
+		case "variables":
+			patch = patch.Variables(task.Variables)
+		case "managedBy":
+			patch = patch.ManagedBy(task.ManagedBy)
+		}
+	}
+	return patch
+}
+
+// Apply executes the plan's creates, updates and deletes in order, stopping
+// at the first error. progress is called after each change is applied.
+func (p Plan) Apply(client *api.Client, progress func(Change)) error {
+	for _, c := range p {
+		var err error
+		switch c.Action {
+		case ActionCreate:
+			_, err = client.CreateTask(c.desired)
+		case ActionUpdate:
+			_, err = client.PatchTask(c.existing.ID, patchFields(c.desired, c.Fields).IfMatch(c.existing.ETag()))
+		case ActionDelete:
+			err = client.DeleteTask(c.existing.ID)
+		default:
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s %q: %w", c.Action, c.Name, err)
+		}
+		if progress != nil {
+			progress(c)
+		}
+	}
+	return nil
+}
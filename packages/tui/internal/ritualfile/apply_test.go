@@ -0,0 +1,50 @@
+// ABOUTME: Tests for planning and applying ritual definitions against the in-memory server
+// ABOUTME: Checks that applying a plan converges, so a second plan has nothing to do
+
+package ritualfile_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/ritualfile"
+	"github.com/jem-computer/ritual/tui/internal/testutil"
+)
+
+func TestApplyClearsDroppedVariables(t *testing.T) {
+	srv := testutil.NewServer(t)
+	task := srv.AddTask(api.Task{
+		Name:      "Digest",
+		Prompt:    "Summarise",
+		Schedule:  "@daily",
+		Status:    "ACTIVE",
+		Variables: map[string]string{"team": "core"},
+		ManagedBy: "rituals/digest.yaml",
+	})
+	client := srv.Client()
+
+	defs := []ritualfile.Definition{{
+		Ritual: ritualfile.Ritual{Name: "Digest", Prompt: "Summarise", Schedule: "@daily"},
+		Source: "rituals/digest.yaml",
+	}}
+
+	plan := ritualfile.PlanApply(defs, srv.Tasks(), "rituals", false)
+	if len(plan) != 1 || plan[0].Action != ritualfile.ActionUpdate || !slices.Equal(plan[0].Fields, []string{"variables"}) {
+		t.Fatalf("got plan %+v, want an update of the variables", plan)
+	}
+	if err := plan.Apply(client, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if current, _ := srv.Task(task.ID); len(current.Variables) > 0 {
+		t.Errorf("server still has variables %v", current.Variables)
+	}
+	tasks, err := client.GetTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again := ritualfile.PlanApply(defs, tasks, "rituals", false); again.HasChanges() {
+		t.Errorf("second plan still has changes: %+v", again)
+	}
+}
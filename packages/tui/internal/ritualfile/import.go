@@ -0,0 +1,149 @@
+// ABOUTME: Imports a ritual document into a server, resolving duplicate names
+// ABOUTME: Duplicates are skipped, overwritten in place or renamed with a numeric suffix
+
+package ritualfile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jem-computer/ritual/tui/internal/api"
+)
+
+// Strategy decides what happens to a ritual whose name already exists
+type Strategy string
+
+const (
+	Skip      Strategy = "skip"
+	Overwrite Strategy = "overwrite"
+	Rename    Strategy = "rename"
+)
+
+// ParseStrategy validates an --on-duplicate style value
+func ParseStrategy(s string) (Strategy, error) {
+	switch Strategy(strings.ToLower(s)) {
+	case Skip:
+		return Skip, nil
+	case Overwrite:
+		return Overwrite, nil
+	case Rename:
+		return Rename, nil
+	}
+	return "", fmt.Errorf("unknown duplicate strategy %q (want skip, overwrite or rename)", s)
+}
+
+// Resolver picks a strategy for one duplicate; existing is the task
+// already on the server
+type Resolver func(r Ritual, existing api.Task) (Strategy, error)
+
+// Always returns a resolver that applies the same strategy to every duplicate
+func Always(s Strategy) Resolver {
+	return func(Ritual, api.Task) (Strategy, error) { return s, nil }
+}
+
+// Result counts what an import did
+type Result struct {
+	Created     []string
+	Overwritten []string
+	Renamed     []string
+	Skipped     []string
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("%d created, %d overwritten, %d renamed, %d skipped",
+		len(r.Created), len(r.Overwritten), len(r.Renamed), len(r.Skipped))
+}
+
+// Duplicates returns the rituals whose names match an existing task
+func Duplicates(doc Document, existing []api.Task) []Ritual {
+	byName := indexByName(existing)
+
+	var dups []Ritual
+	for _, r := range doc.Rituals {
+		if _, ok := byName[r.Name]; ok {
+			dups = append(dups, r)
+		}
+	}
+	return dups
+}
+
+// Import creates the document's rituals on the server. Names are matched
+// against existing tasks and against rituals created earlier in the same
+// import; resolve is consulted for each clash. It stops at the first
+// server error, returning what was done so far.
+func Import(client *api.Client, doc Document, resolve Resolver) (Result, error) {
+	var result Result
+
+	existing, err := client.GetTasks()
+	if err != nil {
+		return result, err
+	}
+	byName := indexByName(existing)
+
+	for _, r := range doc.Rituals {
+		task := r.Task()
+
+		current, duplicate := byName[r.Name]
+		if !duplicate {
+			created, err := client.CreateTask(task)
+			if err != nil {
+				return result, fmt.Errorf("creating %q: %w", r.Name, err)
+			}
+			byName[created.Name] = *created
+			result.Created = append(result.Created, r.Name)
+			continue
+		}
+
+		strategy, err := resolve(r, current)
+		if err != nil {
+			return result, err
+		}
+
+		switch strategy {
+		case Skip:
+			result.Skipped = append(result.Skipped, r.Name)
+
+		case Overwrite:
+			patch := patchFields(task, ritualFields).IfMatch(current.ETag())
+			updated, err := client.PatchTask(current.ID, patch)
+			if err != nil {
+				return result, fmt.Errorf("overwriting %q: %w", r.Name, err)
+			}
+			byName[updated.Name] = *updated
+			result.Overwritten = append(result.Overwritten, r.Name)
+
+		case Rename:
+			task.Name = UniqueName(r.Name, byName)
+			created, err := client.CreateTask(task)
+			if err != nil {
+				return result, fmt.Errorf("creating %q: %w", task.Name, err)
+			}
+			byName[created.Name] = *created
+			result.Renamed = append(result.Renamed, fmt.Sprintf("%s → %s", r.Name, task.Name))
+		}
+	}
+
+	return result, nil
+}
+
+// ritualFields are the task fields a ritual carries, which overwriting
+// replaces
+var ritualFields = []string{"prompt", "schedule", "model", "output", "status", "variables"}
+
+// UniqueName appends " (2)", " (3)", … until the name is unused
+func UniqueName(name string, taken map[string]api.Task) string {
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)", name, n)
+		if _, ok := taken[candidate]; !ok {
+			return candidate
+		}
+	}
+}
+
+func indexByName(tasks []api.Task) map[string]api.Task {
+	byName := make(map[string]api.Task, len(tasks))
+	for _, task := range tasks {
+		byName[task.Name] = task
+	}
+	return byName
+}
@@ -0,0 +1,174 @@
+// ABOUTME: Versioned YAML/JSON file format for exporting and importing rituals
+// ABOUTME: Converts between api.Task and the portable document and validates it
+
+package ritualfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/schedule"
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the current document format version. Decode rejects newer
+// versions rather than silently dropping fields it doesn't understand.
+const Version = 1
+
+// Document is the on-disk representation of a set of rituals
+type Document struct {
+	Version int      `yaml:"version" json:"version"`
+	Rituals []Ritual `yaml:"rituals" json:"rituals"`
+}
+
+// Ritual is the portable subset of a task: everything needed to recreate
+// it on another server, without IDs or run state
+type Ritual struct {
+	Name      string            `yaml:"name" json:"name"`
+	Prompt    string            `yaml:"prompt" json:"prompt"`
+	Schedule  string            `yaml:"schedule" json:"schedule"`
+	Model     string            `yaml:"model,omitempty" json:"model,omitempty"`
+	Output    string            `yaml:"output,omitempty" json:"output,omitempty"`
+	Status    string            `yaml:"status,omitempty" json:"status,omitempty"`
+	Variables map[string]string `yaml:"variables,omitempty" json:"variables,omitempty"`
+}
+
+// Format selects the encoding used by Encode
+type Format string
+
+const (
+	YAML Format = "yaml"
+	JSON Format = "json"
+)
+
+// ParseFormat validates a --format style value
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case YAML, "yml":
+		return YAML, nil
+	case JSON:
+		return JSON, nil
+	}
+	return "", fmt.Errorf("unknown format %q (want yaml or json)", s)
+}
+
+// FromTasks builds a document from server tasks
+func FromTasks(tasks []api.Task) Document {
+	doc := Document{Version: Version, Rituals: make([]Ritual, len(tasks))}
+	for i, task := range tasks {
+		doc.Rituals[i] = FromTask(task)
+	}
+	return doc
+}
+
+// FromTask converts a single task
+func FromTask(task api.Task) Ritual {
+	return Ritual{
+		Name:      task.Name,
+		Prompt:    task.Prompt,
+		Schedule:  task.Schedule,
+		Model:     task.Model,
+		Output:    task.Output,
+		Status:    task.Status,
+		Variables: task.Variables,
+	}
+}
+
+// Task converts the ritual back into a task for CreateTask. A missing
+// status defaults to ACTIVE, and NextRun is computed from the schedule the
+// same way the create form does.
+func (r Ritual) Task() api.Task {
+	status := r.Status
+	if status == "" {
+		status = "ACTIVE"
+	}
+
+	var nextRun time.Time
+	if parsed, err := schedule.Parse(r.Schedule); err == nil {
+		nextRun = parsed.Next(time.Now())
+	}
+
+	return api.Task{
+		NextRun:   nextRun,
+		Name:      r.Name,
+		Prompt:    r.Prompt,
+		Schedule:  r.Schedule,
+		Model:     r.Model,
+		Output:    r.Output,
+		Status:    status,
+		Variables: r.Variables,
+	}
+}
+
+// Encode writes the document in the given format
+func Encode(w io.Writer, doc Document, format Format) error {
+	switch format {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+	default:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+}
+
+// Decode parses a YAML or JSON document and validates it
+func Decode(data []byte) (Document, error) {
+	var doc Document
+
+	// YAML is a superset of JSON, so one decoder covers both formats
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&doc); err != nil {
+		if err == io.EOF {
+			return doc, fmt.Errorf("file is empty")
+		}
+		return doc, err
+	}
+
+	return doc, doc.validate()
+}
+
+func (d Document) validate() error {
+	if d.Version == 0 {
+		return fmt.Errorf("missing version (expected version: %d)", Version)
+	}
+	if d.Version > Version {
+		return fmt.Errorf("unsupported version %d; this ritual understands up to version %d", d.Version, Version)
+	}
+
+	for i, r := range d.Rituals {
+		if err := r.validate(); err != nil {
+			return fmt.Errorf("ritual %d (%q): %w", i+1, r.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r Ritual) validate() error {
+	switch {
+	case strings.TrimSpace(r.Name) == "":
+		return fmt.Errorf("name is required")
+	case strings.TrimSpace(r.Prompt) == "":
+		return fmt.Errorf("prompt is required")
+	case strings.TrimSpace(r.Schedule) == "":
+		return fmt.Errorf("schedule is required")
+	case r.Status != "" && r.Status != "ACTIVE" && r.Status != "PAUSED":
+		return fmt.Errorf("status must be ACTIVE or PAUSED, got %q", r.Status)
+	}
+
+	if _, err := schedule.Parse(r.Schedule); err != nil {
+		return err
+	}
+	return nil
+}
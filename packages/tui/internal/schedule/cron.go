@@ -0,0 +1,149 @@
+// ABOUTME: Five-field cron expression parser backing the Schedule type
+// ABOUTME: Supports lists, ranges, steps, and month/weekday names
+
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type fieldSpec struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteSpec = fieldSpec{name: "minute", min: 0, max: 59}
+	hourSpec   = fieldSpec{name: "hour", min: 0, max: 23}
+	domSpec    = fieldSpec{name: "day of month", min: 1, max: 31}
+	monthSpec  = fieldSpec{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Day of week accepts 7 as an alias for Sunday
+	dowSpec = fieldSpec{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// parseCron builds a Schedule from a five-field cron expression. When
+// description is empty one is derived from the expression.
+func parseCron(expr, cron, description string) (*Schedule, error) {
+	fields := strings.Fields(strings.ToLower(cron))
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression must have 5 fields, got %d", len(fields))
+	}
+
+	s := &Schedule{
+		Expr: expr,
+		Cron: strings.Join(fields, " "),
+	}
+
+	var err error
+	if s.minute, err = parseField(fields[0], minuteSpec); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseField(fields[1], hourSpec); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseField(fields[2], domSpec); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseField(fields[3], monthSpec); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseField(fields[4], dowSpec); err != nil {
+		return nil, err
+	}
+
+	// Fold Sunday-as-7 onto 0
+	if has(s.dow, 7) {
+		s.dow |= 1
+		s.dow &^= 1 << 7
+	}
+
+	s.domStar = isWildcard(fields[2])
+	s.dowStar = isWildcard(fields[4])
+
+	s.Description = description
+	if s.Description == "" {
+		s.Description = describeCron(fields)
+	}
+
+	return s, nil
+}
+
+func isWildcard(field string) bool {
+	return field == "*" || field == "?"
+}
+
+// parseField parses one cron field into a bitset of allowed values
+func parseField(field string, spec fieldSpec) (uint64, error) {
+	var set uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in %s field: %q", spec.name, part)
+			}
+			rangePart, step = part[:i], n
+		}
+
+		var lo, hi int
+		switch {
+		case rangePart == "*" || rangePart == "?":
+			lo, hi = spec.min, spec.max
+			if spec.name == dowSpec.name {
+				hi = 6
+			}
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if lo, err = parseValue(bounds[0], spec); err != nil {
+				return 0, err
+			}
+			if hi, err = parseValue(bounds[1], spec); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range in %s field: %q", spec.name, rangePart)
+			}
+		default:
+			v, err := parseValue(rangePart, spec)
+			if err != nil {
+				return 0, err
+			}
+			lo, hi = v, v
+			// "5/15" means every 15 starting at 5
+			if step > 1 {
+				hi = spec.max
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+
+	return set, nil
+}
+
+func parseValue(s string, spec fieldSpec) (int, error) {
+	if v, ok := spec.names[s]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %q", spec.name, s)
+	}
+	if v < spec.min || v > spec.max {
+		return 0, fmt.Errorf("%s %d out of range %d-%d", spec.name, v, spec.min, spec.max)
+	}
+	return v, nil
+}
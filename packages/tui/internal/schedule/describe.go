@@ -0,0 +1,134 @@
+// ABOUTME: Human-readable descriptions of cron expressions
+// ABOUTME: Renders e.g. "0 9 * * 1-5" as "At 09:00 on Monday–Friday"
+
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var weekdayNames = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+
+var monthNames = []string{"", "January", "February", "March", "April", "May", "June",
+	"July", "August", "September", "October", "November", "December"}
+
+// describeCron renders a normalized five-field cron expression in English.
+// Fields it can't phrase nicely fall back to their raw cron syntax.
+func describeCron(fields []string) string {
+	minute, hour, dom, month, dow := fields[0], fields[1], fields[2], fields[3], fields[4]
+
+	var parts []string
+	parts = append(parts, describeTime(minute, hour))
+
+	if dom != "*" && dom != "?" {
+		parts = append(parts, "on day "+describeList(dom, nil)+" of the month")
+	}
+	if dow != "*" && dow != "?" {
+		prefix := "on "
+		if dom != "*" && dom != "?" {
+			prefix = "or on "
+		}
+		parts = append(parts, prefix+describeList(dow, func(v int) string { return weekdayNames[v] }))
+	}
+	if month != "*" && month != "?" {
+		parts = append(parts, "in "+describeList(month, func(v int) string { return monthNames[v] }))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func describeTime(minute, hour string) string {
+	m, mErr := strconv.Atoi(minute)
+	h, hErr := strconv.Atoi(hour)
+
+	switch {
+	case mErr == nil && hErr == nil:
+		return fmt.Sprintf("At %02d:%02d", h, m)
+	case minute == "*" && hour == "*":
+		return "Every minute"
+	case strings.HasPrefix(minute, "*/") && hour == "*":
+		return fmt.Sprintf("Every %s minutes", minute[2:])
+	case mErr == nil && hour == "*":
+		if m == 0 {
+			return "Every hour"
+		}
+		return fmt.Sprintf("At minute %d past every hour", m)
+	case mErr == nil && strings.HasPrefix(hour, "*/"):
+		if m == 0 {
+			return fmt.Sprintf("Every %s hours", hour[2:])
+		}
+		return fmt.Sprintf("At minute %d past every %s hours", m, hour[2:])
+	case mErr == nil && isNumberList(hour):
+		var times []string
+		for _, part := range strings.Split(hour, ",") {
+			h, _ := strconv.Atoi(part)
+			times = append(times, fmt.Sprintf("%02d:%02d", h, m))
+		}
+		return "At " + describeList(strings.Join(times, ","), nil)
+	case mErr == nil:
+		return fmt.Sprintf("At minute %d past hour %s", m, describeList(hour, nil))
+	default:
+		return fmt.Sprintf("At minute %s past hour %s", describeList(minute, nil), describeList(hour, nil))
+	}
+}
+
+// describeList phrases a field's comma list, turning ranges into "a–b" and
+// mapping numbers through name when given. Name-valued fields (e.g. "mon")
+// are normalized to numbers first.
+func describeList(field string, name func(int) string) string {
+	var items []string
+
+	for _, part := range strings.Split(field, ",") {
+		if strings.Contains(part, "/") {
+			items = append(items, part)
+			continue
+		}
+
+		bounds := strings.SplitN(part, "-", 2)
+		labels := make([]string, len(bounds))
+		for i, b := range bounds {
+			labels[i] = b
+			if name == nil {
+				continue
+			}
+			v, err := strconv.Atoi(b)
+			if err != nil {
+				v, err = lookupName(b)
+			}
+			if err == nil && v >= 0 && v < 13 {
+				labels[i] = name(v)
+			}
+		}
+		items = append(items, strings.Join(labels, "–"))
+	}
+
+	switch len(items) {
+	case 1:
+		return items[0]
+	case 2:
+		return items[0] + " and " + items[1]
+	default:
+		return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+	}
+}
+
+func isNumberList(field string) bool {
+	for _, part := range strings.Split(field, ",") {
+		if _, err := strconv.Atoi(part); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func lookupName(s string) (int, error) {
+	if v, ok := dowSpec.names[s]; ok {
+		return v, nil
+	}
+	if v, ok := monthSpec.names[s]; ok {
+		return v, nil
+	}
+	return 0, fmt.Errorf("unknown name %q", s)
+}
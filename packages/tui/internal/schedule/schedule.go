@@ -0,0 +1,222 @@
+// ABOUTME: Client-side schedule parsing mirroring the server's schedule-parser.ts
+// ABOUTME: Turns natural-language schedules and cron expressions into fire times
+
+package schedule
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// searchYears bounds how far ahead Next looks before giving up, so
+// impossible expressions like "0 0 31 2 *" terminate
+const searchYears = 5
+
+// Schedule is a parsed schedule that can enumerate its fire times
+type Schedule struct {
+	// Expr is the schedule as the user wrote it
+	Expr string
+	// Cron is the equivalent five-field cron expression
+	Cron string
+	// Description is a human-readable interpretation
+	Description string
+
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+var (
+	dailyPattern   = regexp.MustCompile(`daily at (\d{1,2}):(\d{2})\s*(am|pm)?`)
+	weeklyPattern  = regexp.MustCompile(`every (\w+) at (\d{1,2}):(\d{2})\s*(am|pm)?`)
+	hourlyPattern  = regexp.MustCompile(`every (\d+) hours?`)
+	minutePattern  = regexp.MustCompile(`every (\d+) minutes?`)
+	monthlyPattern = regexp.MustCompile(`monthly at (\d{1,2}):(\d{2})\s*(am|pm)?`)
+)
+
+var dayNames = map[string]int{
+	"sunday":    0,
+	"monday":    1,
+	"tuesday":   2,
+	"wednesday": 3,
+	"thursday":  4,
+	"friday":    5,
+	"saturday":  6,
+}
+
+var aliases = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse interprets a schedule string. It accepts the same natural-language
+// forms as the server ("daily at 8:00 AM", "every monday at 9:00",
+// "every 2 hours", "every 15 minutes", "monthly at 6:00 pm"), cron aliases
+// such as "@daily", and plain five-field cron expressions.
+func Parse(expr string) (*Schedule, error) {
+	normalized := strings.ToLower(strings.TrimSpace(expr))
+	if normalized == "" {
+		return nil, fmt.Errorf("schedule is empty")
+	}
+
+	if cron, ok := aliases[normalized]; ok {
+		return parseCron(expr, cron, "")
+	}
+	if strings.HasPrefix(normalized, "@") {
+		return nil, fmt.Errorf("unknown schedule alias: %q", expr)
+	}
+
+	if cron, description, ok, err := parseNatural(normalized); ok || err != nil {
+		if err != nil {
+			return nil, err
+		}
+		return parseCron(expr, cron, description)
+	}
+
+	if len(strings.Fields(normalized)) == 5 {
+		return parseCron(expr, normalized, "")
+	}
+
+	return nil, fmt.Errorf("unable to parse schedule: %q", expr)
+}
+
+// parseNatural ports parseSchedule from the server. ok is false when no
+// pattern matched.
+func parseNatural(normalized string) (cron, description string, ok bool, err error) {
+	if m := dailyPattern.FindStringSubmatch(normalized); m != nil {
+		hour, err := to24Hour(m[1], m[3])
+		if err != nil {
+			return "", "", true, err
+		}
+		return fmt.Sprintf("%s %d * * *", m[2], hour),
+			fmt.Sprintf("Daily at %02d:%s", hour, m[2]), true, nil
+	}
+
+	if m := weeklyPattern.FindStringSubmatch(normalized); m != nil {
+		day, known := dayNames[m[1]]
+		if !known {
+			return "", "", true, fmt.Errorf("invalid day name: %s", m[1])
+		}
+		hour, err := to24Hour(m[2], m[4])
+		if err != nil {
+			return "", "", true, err
+		}
+		return fmt.Sprintf("%s %d * * %d", m[3], hour, day),
+			fmt.Sprintf("Every %s at %02d:%s", m[1], hour, m[3]), true, nil
+	}
+
+	if m := hourlyPattern.FindStringSubmatch(normalized); m != nil {
+		return fmt.Sprintf("0 */%s * * *", m[1]),
+			fmt.Sprintf("Every %s hour%s", m[1], plural(m[1])), true, nil
+	}
+
+	if m := minutePattern.FindStringSubmatch(normalized); m != nil {
+		return fmt.Sprintf("*/%s * * * *", m[1]),
+			fmt.Sprintf("Every %s minute%s", m[1], plural(m[1])), true, nil
+	}
+
+	if m := monthlyPattern.FindStringSubmatch(normalized); m != nil {
+		hour, err := to24Hour(m[1], m[3])
+		if err != nil {
+			return "", "", true, err
+		}
+		return fmt.Sprintf("%s %d 1 * *", m[2], hour),
+			fmt.Sprintf("Monthly on the 1st at %02d:%s", hour, m[2]), true, nil
+	}
+
+	return "", "", false, nil
+}
+
+func to24Hour(hourStr, ampm string) (int, error) {
+	hour, err := strconv.Atoi(hourStr)
+	if err != nil {
+		return 0, err
+	}
+
+	if ampm == "pm" && hour != 12 {
+		hour += 12
+	} else if ampm == "am" && hour == 12 {
+		hour = 0
+	}
+
+	if hour > 23 {
+		return 0, fmt.Errorf("invalid hour: %s", hourStr)
+	}
+	return hour, nil
+}
+
+func plural(n string) string {
+	if n == "1" {
+		return ""
+	}
+	return "s"
+}
+
+// Next returns the first fire time strictly after t, in t's location, or
+// the zero time if the schedule never fires within the search window.
+func (s *Schedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + searchYears
+	loc := t.Location()
+
+	for t.Year() <= limit {
+		switch {
+		case !has(s.month, int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case !has(s.hour, t.Hour()):
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+		case !has(s.minute, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// Between returns fire times in [from, to), capped at limit entries
+func (s *Schedule) Between(from, to time.Time, limit int) []time.Time {
+	var times []time.Time
+	for t := s.Next(from.Add(-time.Nanosecond)); !t.IsZero() && t.Before(to); t = s.Next(t) {
+		if len(times) >= limit {
+			break
+		}
+		times = append(times, t)
+	}
+	return times
+}
+
+// Upcoming returns the next n fire times after t
+func (s *Schedule) Upcoming(after time.Time, n int) []time.Time {
+	var times []time.Time
+	for t := s.Next(after); !t.IsZero() && len(times) < n; t = s.Next(t) {
+		times = append(times, t)
+	}
+	return times
+}
+
+// dayMatches applies cron's day rule: when both day-of-month and
+// day-of-week are restricted, either one matching is enough
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := has(s.dom, t.Day())
+	dowMatch := has(s.dow, int(t.Weekday()))
+
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+func has(set uint64, v int) bool {
+	return set&(1<<uint(v)) != 0
+}
@@ -0,0 +1,162 @@
+// ABOUTME: Table tests for schedule parsing and fire times
+// ABOUTME: Checks Parse and Next agree with the server's schedule-parser.ts and cron-parser
+
+package schedule_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jem-computer/ritual/tui/internal/schedule"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr string
+		cron string
+	}{
+		{"@yearly", "0 0 1 1 *"},
+		{"@annually", "0 0 1 1 *"},
+		{"@monthly", "0 0 1 * *"},
+		{"@weekly", "0 0 * * 0"},
+		{"@daily", "0 0 * * *"},
+		{"@midnight", "0 0 * * *"},
+		{" @HOURLY ", "0 * * * *"},
+		{"daily at 8:00 AM", "00 8 * * *"},
+		{"daily at 12:30 am", "30 0 * * *"},
+		{"daily at 12:15 pm", "15 12 * * *"},
+		{"daily at 18:45", "45 18 * * *"},
+		{"every Monday at 9:00 am", "00 9 * * 1"},
+		{"every sunday at 7:30 pm", "30 19 * * 0"},
+		{"every 2 hours", "0 */2 * * *"},
+		{"every 1 hour", "0 */1 * * *"},
+		{"every 15 minutes", "*/15 * * * *"},
+		{"monthly at 6:00 pm", "00 18 1 * *"},
+		{"0 9 * * 1-5", "0 9 * * 1-5"},
+		{"0  12 1 JAN,jul  *", "0 12 1 jan,jul *"},
+	}
+
+	for _, tt := range tests {
+		s, err := schedule.Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		if s.Cron != tt.cron {
+			t.Errorf("Parse(%q).Cron = %q, want %q", tt.expr, s.Cron, tt.cron)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"@fortnightly",
+		"every funday at 9:00",
+		"daily at 25:00",
+		"0 0 * *",
+		"60 * * * *",
+		"0 24 * * *",
+		"0 0 0 * *",
+		"0 0 32 * *",
+		"0 0 * 13 *",
+		"0 0 * * 8",
+		"*/0 * * * *",
+		"0 0 20-10 * *",
+		"whenever",
+	} {
+		if s, err := schedule.Parse(expr); err == nil {
+			t.Errorf("Parse(%q) = %q, want an error", expr, s.Cron)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	at := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{"strictly after", "0 9 * * *", at(2026, 10, 18, 9, 0), at(2026, 10, 19, 9, 0)},
+		{"seconds round up", "@hourly", time.Date(2026, 10, 18, 10, 0, 30, 0, time.UTC), at(2026, 10, 18, 11, 0)},
+		{"minute step", "*/15 * * * *", at(2026, 10, 18, 10, 7), at(2026, 10, 18, 10, 15)},
+		{"hour step", "every 2 hours", at(2026, 10, 18, 11, 0), at(2026, 10, 18, 12, 0)},
+		{"hour step wraps to the next day", "0 */2 * * *", at(2026, 10, 18, 23, 30), at(2026, 10, 19, 0, 0)},
+		{"step from a start", "5/15 * * * *", at(2026, 10, 18, 10, 21), at(2026, 10, 18, 10, 35)},
+		{"step over a range", "0-30/10 * * * *", at(2026, 10, 18, 10, 25), at(2026, 10, 18, 10, 30)},
+		{"step over a range wraps", "0-30/10 * * * *", at(2026, 10, 18, 10, 31), at(2026, 10, 18, 11, 0)},
+		{"list", "0 8,20 * * *", at(2026, 10, 18, 9, 0), at(2026, 10, 18, 20, 0)},
+		{"weekday", "every monday at 9:00 am", at(2026, 10, 17, 12, 0), at(2026, 10, 19, 9, 0)},
+		{"weekday range skips the weekend", "0 9 * * mon-fri", at(2026, 10, 16, 10, 0), at(2026, 10, 19, 9, 0)},
+		{"sunday as 7", "0 0 * * 7", at(2026, 10, 1, 0, 0), at(2026, 10, 4, 0, 0)},
+		{"day of month", "0 0 13 * *", at(2026, 10, 1, 0, 0), at(2026, 10, 13, 0, 0)},
+		{"day of month or week, week first", "0 0 13 * 5", at(2026, 10, 1, 0, 0), at(2026, 10, 2, 0, 0)},
+		{"day of month or week, month first", "0 0 13 * 5", at(2026, 10, 10, 0, 0), at(2026, 10, 13, 0, 0)},
+		{"restricted day of week with a star month day", "0 0 * * 5", at(2026, 10, 10, 0, 0), at(2026, 10, 16, 0, 0)},
+		{"month names", "0 12 1 jan,jul *", at(2026, 2, 1, 0, 0), at(2026, 7, 1, 12, 0)},
+		{"monthly after a month end", "@monthly", at(2026, 1, 31, 10, 0), at(2026, 2, 1, 0, 0)},
+		{"31st skips short months", "0 0 31 * *", at(2026, 4, 1, 0, 0), at(2026, 5, 31, 0, 0)},
+		{"30th skips february", "0 0 30 * *", at(2026, 1, 31, 0, 0), at(2026, 3, 30, 0, 0)},
+		{"29th of february waits for a leap year", "0 0 29 2 *", at(2026, 3, 1, 0, 0), at(2028, 2, 29, 0, 0)},
+		{"year end", "@yearly", at(2026, 12, 31, 23, 59), at(2027, 1, 1, 0, 0)},
+		{"never", "0 0 31 2 *", at(2026, 1, 1, 0, 0), time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := schedule.Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("Parse(%q).Next(%s) = %s, want %s", tt.expr, tt.after.Format(time.RFC3339), got.Format(time.RFC3339), tt.want.Format(time.RFC3339))
+			}
+		})
+	}
+}
+
+func TestNextKeepsLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+10", 10*60*60)
+	s, err := schedule.Parse("daily at 8:00 am")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := s.Next(time.Date(2026, 10, 18, 9, 0, 0, 0, loc))
+	if want := time.Date(2026, 10, 19, 8, 0, 0, 0, loc); !got.Equal(want) || got.Location() != loc {
+		t.Errorf("Next = %s, want %s", got, want)
+	}
+}
+
+func TestUpcomingAndBetween(t *testing.T) {
+	s, err := schedule.Parse("every 6 hours")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	upcoming := s.Upcoming(from, 3)
+	want := []time.Time{from.Add(6 * time.Hour), from.Add(12 * time.Hour), from.Add(18 * time.Hour)}
+	if len(upcoming) != len(want) {
+		t.Fatalf("Upcoming = %v, want %v", upcoming, want)
+	}
+	for i := range want {
+		if !upcoming[i].Equal(want[i]) {
+			t.Errorf("Upcoming[%d] = %s, want %s", i, upcoming[i], want[i])
+		}
+	}
+
+	// Between includes from itself and stops short of to
+	between := s.Between(from, from.Add(24*time.Hour), 10)
+	if len(between) != 4 || !between[0].Equal(from) {
+		t.Errorf("Between = %v, want the 4 fire times from midnight", between)
+	}
+	if capped := s.Between(from, from.Add(24*time.Hour), 2); len(capped) != 2 {
+		t.Errorf("Between with a limit of 2 returned %d times", len(capped))
+	}
+}
@@ -0,0 +1,238 @@
+// ABOUTME: Task template library with built-in templates and user templates on disk
+// ABOUTME: Loads and saves YAML templates under ~/.config/ritual/templates/
+
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Template pre-fills the create form
+type Template struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description,omitempty"`
+	Prompt      string            `yaml:"prompt"`
+	Schedule    string            `yaml:"schedule"`
+	Model       string            `yaml:"model,omitempty"`
+	Output      string            `yaml:"output,omitempty"`
+	Variables   map[string]string `yaml:"variables,omitempty"`
+
+	// Path is the file a user template was loaded from; empty for built-ins
+	Path string `yaml:"-"`
+}
+
+// Builtin reports whether the template ships with ritual
+func (t Template) Builtin() bool {
+	return t.Path == ""
+}
+
+// Task returns the task the template describes, ready for the create form
+func (t Template) Task() api.Task {
+	return api.Task{
+		Name:      t.Name,
+		Prompt:    t.Prompt,
+		Schedule:  t.Schedule,
+		Model:     t.Model,
+		Output:    t.Output,
+		Variables: t.Variables,
+	}
+}
+
+// FromTask captures an existing task as a template
+func FromTask(task api.Task) Template {
+	return Template{
+		Name:        task.Name,
+		Description: fmt.Sprintf("Saved from task %q", task.Name),
+		Prompt:      task.Prompt,
+		Schedule:    task.Schedule,
+		Model:       task.Model,
+		Output:      task.Output,
+		Variables:   task.Variables,
+	}
+}
+
+var builtins = []Template{
+	{
+		Name:        "Daily Standup",
+		Description: "Morning summary of open tasks and tickets, ready to paste into standup",
+		Prompt: `Summarize my open Things tasks and Linear tickets for {{date "Monday, January 2"}} ` +
+			`as a brief standup update: what I did yesterday, what I'm doing today, and any blockers.`,
+		Schedule: "0 9 * * 1-5",
+		Model:    "claude-3-5-haiku-20241022",
+		Output:   "Slack #standup",
+	},
+	{
+		Name:        "Weekly Report",
+		Description: "Sunday productivity report from calendar and git commits",
+		Prompt: `Generate a productivity report for the week ending {{date "January 2, 2006"}} ` +
+			`based on my calendar and the git commits in {{var "repo"}}. ` +
+			`Compare against last week's report where relevant:` + "\n\n{{last_output}}",
+		Schedule:  "every sunday at 6:00 pm",
+		Model:     "claude-3-5-sonnet-20241022",
+		Output:    "Email to me",
+		Variables: map[string]string{"repo": ""},
+	},
+	{
+		Name:        "Commit Summary",
+		Description: "End-of-day summary of today's commits for the team channel",
+		Prompt: `Summarize today's ({{date "2006-01-02"}}) commits in {{var "repo"}} for the team. ` +
+			`Group them by feature and call out anything that needs review.`,
+		Schedule:  "0 18 * * 1-5",
+		Model:     "claude-3-5-haiku-20241022",
+		Output:    "Slack #dev-team",
+		Variables: map[string]string{"repo": ""},
+	},
+	{
+		Name:        "Content Ideas",
+		Description: "Monday batch of blog post ideas",
+		Prompt: `Generate 5 blog post ideas for the week of {{date "January 2"}} based on trending topics in {{var "topic"}}. ` +
+			`Avoid repeating these ideas from last week:` + "\n\n{{last_output}}",
+		Schedule:  "every monday at 8:00 am",
+		Model:     "claude-3-5-sonnet-20241022",
+		Output:    "Email to me",
+		Variables: map[string]string{"topic": ""},
+	},
+	{
+		Name:        "Overdue Task Reminder",
+		Description: "Checks for overdue tasks and texts you if any exist",
+		Prompt:      `Check my Things tasks for anything overdue as of {{date "Monday 15:04"}}. If there are any, list them; otherwise reply "Nothing overdue".`,
+		Schedule:    "daily at 5:00 pm",
+		Model:       "claude-3-5-haiku-20241022",
+		Output:      "SMS",
+	},
+}
+
+// Builtins returns the templates that ship with ritual
+func Builtins() []Template {
+	out := make([]Template, len(builtins))
+	copy(out, builtins)
+	return out
+}
+
+// Dir returns the user template directory, honouring $XDG_CONFIG_HOME
+func Dir() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "templates"), nil
+}
+
+// Load returns the built-in templates followed by the user's, sorted by
+// name. Files that fail to parse are reported alongside the templates that
+// did load rather than failing the whole library.
+func Load() ([]Template, []error) {
+	all := Builtins()
+
+	dir, err := Dir()
+	if err != nil {
+		return all, []error{err}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return all, nil
+	}
+	if err != nil {
+		return all, []error{err}
+	}
+
+	var user []Template
+	var errs []error
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+
+		t, err := loadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		user = append(user, t)
+	}
+
+	sort.Slice(user, func(i, j int) bool {
+		return strings.ToLower(user[i].Name) < strings.ToLower(user[j].Name)
+	})
+
+	return append(all, user...), errs
+}
+
+func loadFile(path string) (Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Template{}, err
+	}
+
+	// YAML is a superset of JSON, so .json templates parse here too
+	var t Template
+	if err := yaml.Unmarshal(data, &t); err != nil {
+		return Template{}, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	if t.Name == "" || t.Prompt == "" {
+		return Template{}, fmt.Errorf("%s: template needs a name and a prompt", filepath.Base(path))
+	}
+
+	t.Path = path
+	return t, nil
+}
+
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Save writes the template to the user template directory and returns its
+// path. An existing template with the same name is never overwritten; the
+// file gets a numeric suffix instead.
+func Save(t Template) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	data, err := yaml.Marshal(t)
+	if err != nil {
+		return "", err
+	}
+
+	slug := strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(t.Name), "-"), "-")
+	if slug == "" {
+		slug = "template"
+	}
+
+	for n := 1; ; n++ {
+		name := slug + ".yaml"
+		if n > 1 {
+			name = fmt.Sprintf("%s-%d.yaml", slug, n)
+		}
+
+		path := filepath.Join(dir, name)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		_, err = f.Write(data)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		return path, err
+	}
+}
@@ -0,0 +1,310 @@
+// ABOUTME: Bubbletea test driver that feeds a model key presses and messages without a terminal
+// ABOUTME: Runs the commands the model returns until it settles and renders frames for golden files
+
+package testutil
+
+import (
+	"bytes"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// DefaultWait is how long the driver waits for a command, such as a request
+// to the test server, before failing the test. It's generous so a slow
+// machine doesn't lose a result; a command that takes this long is a bug.
+const DefaultWait = 10 * time.Second
+
+// pollInterval is how often the driver checks whether a command it's
+// waiting for has settled into a background wait
+const pollInterval = time.Millisecond
+
+// maxRounds bounds how many rounds of follow-up commands one step runs,
+// in case a model keeps answering its own messages
+const maxRounds = 50
+
+// Driver runs a model the way the Bubbletea runtime would, one message at a
+// time, but synchronously: each step delivers a message, then runs the
+// commands it returned, delivers their results, and so on until there are
+// no more. Background commands, which wait on a timer or an event, are
+// abandoned once they start waiting, so a frame never depends on timers
+// firing; any other command is waited for.
+type Driver struct {
+	t     testing.TB
+	model tea.Model
+	Wait  time.Duration
+}
+
+// NewDriver initialises model and sizes it to width×height
+func NewDriver(t testing.TB, model tea.Model, width, height int) *Driver {
+	t.Helper()
+
+	d := &Driver{t: t, Wait: DefaultWait}
+	model, cmd := model.Init()
+	d.model = model
+	d.settle(cmd)
+	d.Send(tea.WindowSizeMsg{Width: width, Height: height})
+	return d
+}
+
+// Model returns the model as it is now, for assertions on its state
+func (d *Driver) Model() tea.Model {
+	return d.model
+}
+
+// Send delivers msg and waits for the model to settle
+func (d *Driver) Send(msg tea.Msg) {
+	d.t.Helper()
+	var cmd tea.Cmd
+	d.model, cmd = d.model.Update(msg)
+	d.settle(cmd)
+}
+
+// Press delivers key presses named as Bubbletea prints them, e.g. "down",
+// "enter", "ctrl+p" or "C"
+func (d *Driver) Press(keys ...string) {
+	d.t.Helper()
+	for _, name := range keys {
+		d.Send(Key(name))
+	}
+}
+
+// Type delivers text as a key press per character
+func (d *Driver) Type(text string) {
+	d.t.Helper()
+	for _, r := range text {
+		d.Send(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+}
+
+// Resize changes the window size
+func (d *Driver) Resize(width, height int) {
+	d.t.Helper()
+	d.Send(tea.WindowSizeMsg{Width: width, Height: height})
+}
+
+// View renders the current frame as plain text: styling is stripped and
+// trailing spaces trimmed, so frames compare the same whatever the theme's
+// colors or the terminal
+func (d *Driver) View() string {
+	view := ansi.Strip(d.model.View())
+	lines := strings.Split(view, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Golden compares the current frame with testdata/<name>.golden
+func (d *Driver) Golden(name string) {
+	d.t.Helper()
+	Golden(d.t, name, d.View())
+}
+
+// settle runs cmd and everything that follows from it
+func (d *Driver) settle(cmd tea.Cmd) {
+	pending := []tea.Cmd{cmd}
+	for round := 0; len(pending) > 0; round++ {
+		if round == maxRounds {
+			d.t.Fatalf("model didn't settle after %d rounds of commands", maxRounds)
+		}
+
+		msgs, next := d.run(pending)
+		for _, msg := range msgs {
+			var cmd tea.Cmd
+			d.model, cmd = d.model.Update(msg)
+			next = append(next, cmd)
+		}
+		pending = next
+	}
+}
+
+// run runs cmds concurrently, as the runtime does, and returns the messages
+// they produced in the order the commands were given, so frames don't
+// depend on scheduling. The commands of batches and sequences they produced
+// are returned to run next; other runtime messages, like tea.Quit's, are
+// dropped. Background commands produce nothing.
+func (d *Driver) run(cmds []tea.Cmd) (msgs []tea.Msg, next []tea.Cmd) {
+	d.t.Helper()
+
+	results := make([]chan tea.Msg, len(cmds))
+	goroutines := make([]chan string, len(cmds))
+	for i, cmd := range cmds {
+		if cmd == nil {
+			continue
+		}
+		// Buffered so an abandoned command can still finish
+		results[i] = make(chan tea.Msg, 1)
+		goroutines[i] = make(chan string, 1)
+		go func() {
+			goroutines[i] <- goroutineID()
+			results[i] <- cmd()
+		}()
+	}
+
+	deadline := time.After(d.Wait)
+	for i, result := range results {
+		if result == nil {
+			continue
+		}
+		id := <-goroutines[i]
+
+		var msg tea.Msg
+	wait:
+		for {
+			select {
+			case msg = <-result:
+				break wait
+			case <-time.After(pollInterval):
+				if background(id) {
+					break wait
+				}
+			case <-deadline:
+				d.t.Fatalf("a command didn't finish within %s; if it waits on a timer or an event, add the function it waits in to backgroundWaits", d.Wait)
+			}
+		}
+
+		switch msg := msg.(type) {
+		case nil:
+		case tea.BatchMsg:
+			next = append(next, msg...)
+		default:
+			if sequence, ok := sequenceCmds(msg); ok {
+				next = append(next, sequence...)
+				continue
+			}
+			if runtimeMsg(msg) {
+				continue
+			}
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs, next
+}
+
+// backgroundWaits are the functions a command blocks in when it waits on a
+// timer or an event rather than does work: ticks, cursor blinks and waits
+// for the connection to change. They're recognised on the command's stack
+// so wrappers, like the TUI's generation tags, don't hide them.
+var backgroundWaits = []string{
+	"github.com/charmbracelet/bubbletea/v2.Tick.func1(",
+	"github.com/charmbracelet/bubbletea/v2.Every.func1(",
+	"github.com/charmbracelet/bubbles/v2/cursor.(*Model).BlinkCmd.func1(",
+	"github.com/jem-computer/ritual/tui/internal/components/common.WaitForConnection.func1(",
+}
+
+// goroutineID returns the ID of the calling goroutine as runtime.Stack
+// prints it
+func goroutineID() string {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	id, _, _ := strings.Cut(strings.TrimPrefix(string(buf), "goroutine "), " ")
+	return id
+}
+
+// background reports whether goroutine id is blocked in one of
+// backgroundWaits
+func background(id string) bool {
+	buf := make([]byte, 1<<20)
+	buf = buf[:runtime.Stack(buf, true)]
+
+	header := []byte("goroutine " + id + " [")
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		if !bytes.HasPrefix(stack, header) {
+			continue
+		}
+		state, _, _ := bytes.Cut(stack[len(header):], []byte("]"))
+		if !bytes.HasPrefix(state, []byte("chan receive")) && !bytes.HasPrefix(state, []byte("select")) {
+			return false
+		}
+		for _, wait := range backgroundWaits {
+			if bytes.Contains(stack, []byte(wait)) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// sequenceCmds unpacks the message tea.Sequence produces, which isn't
+// exported
+func sequenceCmds(msg tea.Msg) ([]tea.Cmd, bool) {
+	v := reflect.ValueOf(msg)
+	if !runtimeMsg(msg) || v.Kind() != reflect.Slice || v.Type().Elem() != reflect.TypeOf(tea.Cmd(nil)) {
+		return nil, false
+	}
+	cmds := make([]tea.Cmd, v.Len())
+	for i := range cmds {
+		cmds[i] = v.Index(i).Interface().(tea.Cmd)
+	}
+	return cmds, true
+}
+
+// runtimeMsg reports whether msg is one of Bubbletea's unexported messages
+// to the runtime, such as quitting or setting the window title
+func runtimeMsg(msg tea.Msg) bool {
+	t := reflect.TypeOf(msg)
+	if t.PkgPath() != reflect.TypeOf(tea.BatchMsg{}).PkgPath() {
+		return false
+	}
+	name, _ := utf8.DecodeRuneInString(t.Name())
+	return name >= 'a' && name <= 'z'
+}
+
+// namedKeys are the keys that print as a name rather than a character
+var namedKeys = map[string]rune{
+	"enter":     tea.KeyEnter,
+	"tab":       tea.KeyTab,
+	"esc":       tea.KeyEscape,
+	"backspace": tea.KeyBackspace,
+	"space":     tea.KeySpace,
+	"up":        tea.KeyUp,
+	"down":      tea.KeyDown,
+	"left":      tea.KeyLeft,
+	"right":     tea.KeyRight,
+	"home":      tea.KeyHome,
+	"end":       tea.KeyEnd,
+	"pgup":      tea.KeyPgUp,
+	"pgdown":    tea.KeyPgDown,
+	"delete":    tea.KeyDelete,
+}
+
+// Key builds the key press that Bubbletea prints as name, e.g. "ctrl+p",
+// "shift+tab" or "q"
+func Key(name string) tea.KeyPressMsg {
+	var key tea.KeyPressMsg
+	for {
+		switch {
+		case strings.HasPrefix(name, "ctrl+"):
+			key.Mod |= tea.ModCtrl
+			name = strings.TrimPrefix(name, "ctrl+")
+			continue
+		case strings.HasPrefix(name, "alt+"):
+			key.Mod |= tea.ModAlt
+			name = strings.TrimPrefix(name, "alt+")
+			continue
+		case strings.HasPrefix(name, "shift+"):
+			key.Mod |= tea.ModShift
+			name = strings.TrimPrefix(name, "shift+")
+			continue
+		}
+		break
+	}
+
+	if code, ok := namedKeys[name]; ok {
+		key.Code = code
+		return key
+	}
+	key.Code, _ = utf8.DecodeRuneInString(name)
+	if key.Mod == 0 || key.Mod == tea.ModShift {
+		key.Text = name
+	}
+	return key
+}
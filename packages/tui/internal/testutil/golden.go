@@ -0,0 +1,48 @@
+// ABOUTME: Golden file comparison for rendered frames and other test output
+// ABOUTME: Run the tests with -update to rewrite the files from the current output
+
+package testutil
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files from the current output")
+
+// Golden compares got with testdata/<name>.golden in the test's package,
+// or with -update writes got there instead. On a mismatch it reports the
+// first line that differs along with both versions.
+func Golden(t testing.TB, name, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if got == string(want) {
+		return
+	}
+
+	gotLines, wantLines := strings.Split(got, "\n"), strings.Split(string(want), "\n")
+	line := 0
+	for line < min(len(gotLines), len(wantLines)) && gotLines[line] == wantLines[line] {
+		line++
+	}
+	t.Errorf("%s differs from line %d (run with -update to accept)\n--- got\n%s\n--- want\n%s",
+		path, line+1, got, want)
+}
@@ -0,0 +1,412 @@
+// ABOUTME: In-memory fake of the Ritual server for tests
+// ABOUTME: Serves the task, log and health endpoints over httptest for use with api.NewClient
+
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jem-computer/ritual/tui/internal/api"
+)
+
+// patchableFields are the fields a PATCH may set, as on the real server
+var patchableFields = map[string]bool{
+	"name": true, "prompt": true, "schedule": true, "model": true, "output": true,
+	"status": true, "variables": true, "managedBy": true,
+}
+
+// Server is an in-memory Ritual server. It answers like the real one,
+// including ETags, conditional updates and error codes, but keeps its
+// tasks and logs in memory and never runs anything: a run request is only
+// recorded. The real server has no event stream yet, so neither does this.
+type Server struct {
+	URL string
+
+	srv *httptest.Server
+
+	mu     sync.Mutex
+	tasks  []api.Task // in creation order
+	logs   []api.LogEntry
+	runs   []string // IDs of tasks run on request, in order
+	token  string
+	down   bool
+	nextID int
+}
+
+// NewServer starts an empty fake server that is shut down when the test
+// ends
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	s := &Server{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", s.health)
+	mux.HandleFunc("GET /api/tasks", s.listTasks)
+	mux.HandleFunc("POST /api/tasks", s.createTask)
+	mux.HandleFunc("GET /api/tasks/{id}", s.getTask)
+	mux.HandleFunc("PUT /api/tasks/{id}", s.changeTask)
+	mux.HandleFunc("PATCH /api/tasks/{id}", s.changeTask)
+	mux.HandleFunc("DELETE /api/tasks/{id}", s.deleteTask)
+	mux.HandleFunc("POST /api/tasks/{id}/run", s.runTask)
+	mux.HandleFunc("GET /api/logs", s.listLogs)
+	mux.HandleFunc("GET /api/tasks/{id}/logs", s.taskLogs)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "Not found", "not_found")
+	})
+
+	s.srv = httptest.NewServer(s.middleware(mux))
+	s.URL = s.srv.URL
+	t.Cleanup(s.srv.Close)
+	return s
+}
+
+// Client returns a client for the server that fails fast instead of
+// retrying, carrying the server's token if it requires one
+func (s *Server) Client() *api.Client {
+	client := api.NewClient(s.URL).WithRetry(api.RetryPolicy{MaxAttempts: 1})
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" {
+		client = client.WithToken(s.token)
+	}
+	return client
+}
+
+// RequireToken makes the API answer 401 unless requests carry token
+func (s *Server) RequireToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+}
+
+// SetDown makes every request fail with a dropped connection, as if the
+// server had gone away, until it's called again with false
+func (s *Server) SetDown(down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.down = down
+}
+
+// AddTask stores task as is, filling in an ID and timestamps when they're
+// missing, and returns the stored copy
+func (s *Server) AddTask(task api.Task) api.Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if task.ID == "" {
+		task.ID = s.newID("task")
+	}
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = now()
+	}
+	if task.UpdatedAt.IsZero() {
+		task.UpdatedAt = task.CreatedAt
+	}
+	s.tasks = append(s.tasks, task)
+	return task
+}
+
+// AddLog stores a log entry, filling in its ID and task name when they're
+// missing
+func (s *Server) AddLog(entry api.LogEntry) api.LogEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry.ID == "" {
+		entry.ID = s.newID("log")
+	}
+	if entry.TaskName == "" {
+		if i := s.find(entry.TaskID); i >= 0 {
+			entry.TaskName = s.tasks[i].Name
+		}
+	}
+	s.logs = append(s.logs, entry)
+	return entry
+}
+
+// RecordRun sets the task's LastRun the way the worker does after a run.
+// Like every change to this server, it gives the task a new ETag.
+func (s *Server) RecordRun(id string, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.find(id); i >= 0 {
+		s.tasks[i].LastRun = at
+		s.tasks[i].UpdatedAt = s.tasks[i].UpdatedAt.Add(time.Millisecond)
+	}
+}
+
+// Tasks returns the stored tasks in creation order
+func (s *Server) Tasks() []api.Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]api.Task(nil), s.tasks...)
+}
+
+// Task returns the stored task with id
+func (s *Server) Task(id string) (api.Task, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.find(id); i >= 0 {
+		return s.tasks[i], true
+	}
+	return api.Task{}, false
+}
+
+// Runs returns the IDs of the tasks run on request, in order
+func (s *Server) Runs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.runs...)
+}
+
+// newID returns a fresh ID such as "task-0001"; the caller holds mu
+func (s *Server) newID(kind string) string {
+	s.nextID++
+	return fmt.Sprintf("%s-%04d", kind, s.nextID)
+}
+
+// find returns the index of the task with id, or -1; the caller holds mu
+func (s *Server) find(id string) int {
+	for i, task := range s.tasks {
+		if task.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// middleware drops connections while the server is down and checks the
+// bearer token on the API
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		down, token := s.down, s.token
+		s.mu.Unlock()
+
+		if down {
+			if hj, ok := w.(http.Hijacker); ok {
+				if conn, _, err := hj.Hijack(); err == nil {
+					conn.Close()
+					return
+				}
+			}
+			writeError(w, http.StatusServiceUnavailable, "Server down", "unavailable")
+			return
+		}
+
+		if token != "" && strings.HasPrefix(r.URL.Path, "/api/") {
+			header := r.Header.Get("Authorization")
+			given, ok := strings.CutPrefix(header, "Bearer ")
+			switch {
+			case !ok:
+				w.Header().Set("WWW-Authenticate", `Bearer realm="ritual"`)
+				writeError(w, http.StatusUnauthorized, "Missing bearer token", "unauthorized")
+				return
+			case given != token:
+				w.Header().Set("WWW-Authenticate", `Bearer realm="ritual", error="invalid_token"`)
+				writeError(w, http.StatusUnauthorized, "Invalid bearer token", "unauthorized")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Handlers
+
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "redis": "connected", "database": "connected"})
+}
+
+func (s *Server) listTasks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	tasks := append([]api.Task(nil), s.tasks...)
+	s.mu.Unlock()
+
+	// Newest first, like the real server
+	sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].CreatedAt.After(tasks[j].CreatedAt) })
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+func (s *Server) createTask(w http.ResponseWriter, r *http.Request) {
+	var task api.Task
+	if err := json.NewDecoder(r.Body).Decode(&task); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON", "invalid_json")
+		return
+	}
+
+	s.mu.Lock()
+	task.ID = s.newID("task")
+	task.CreatedAt = now()
+	task.UpdatedAt = task.CreatedAt
+	task.NextRun, task.LastRun = time.Time{}, time.Time{}
+	s.tasks = append(s.tasks, task)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, task)
+}
+
+func (s *Server) getTask(w http.ResponseWriter, r *http.Request) {
+	task, ok := s.Task(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "Task not found", "task_not_found")
+		return
+	}
+	w.Header().Set("ETag", task.ETag())
+	writeJSON(w, http.StatusOK, task)
+}
+
+// changeTask serves PUT and PATCH, which differ only in that PATCH may
+// set fewer fields
+func (s *Server) changeTask(w http.ResponseWriter, r *http.Request) {
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON", "invalid_json")
+		return
+	}
+	if r.Method == http.MethodPatch {
+		var rejected []string
+		for field := range body {
+			if !patchableFields[field] {
+				rejected = append(rejected, field)
+			}
+		}
+		if len(rejected) > 0 {
+			sort.Strings(rejected)
+			writeError(w, http.StatusBadRequest, "Fields can't be patched: "+strings.Join(rejected, ", "), "invalid_patch")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.find(r.PathValue("id"))
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Task not found", "task_not_found")
+		return
+	}
+	task := s.tasks[i]
+
+	if condition := r.Header.Get("If-Match"); condition != "" && !ifMatch(condition, task) {
+		w.Header().Set("ETag", task.ETag())
+		writeError(w, http.StatusPreconditionFailed, "Task was changed since it was read", "task_conflict")
+		return
+	}
+
+	if _, ok := body["variables"]; ok {
+		// Replaced rather than merged into
+		task.Variables = nil
+	}
+	delete(body, "id")
+	data, _ := json.Marshal(body)
+	if err := json.Unmarshal(data, &task); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid task: "+err.Error(), "invalid_task")
+		return
+	}
+
+	// Every change gets a new ETag, even within the same millisecond
+	updated := now()
+	if !updated.After(task.UpdatedAt) {
+		updated = task.UpdatedAt.Add(time.Millisecond)
+	}
+	task.UpdatedAt = updated
+	s.tasks[i] = task
+
+	w.Header().Set("ETag", task.ETag())
+	writeJSON(w, http.StatusOK, task)
+}
+
+func (s *Server) deleteTask(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.find(r.PathValue("id"))
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Task not found", "task_not_found")
+		return
+	}
+	s.tasks = append(s.tasks[:i:i], s.tasks[i+1:]...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) runTask(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	if s.find(id) < 0 {
+		writeError(w, http.StatusNotFound, "Task not found", "task_not_found")
+		return
+	}
+	s.runs = append(s.runs, id)
+	writeJSON(w, http.StatusAccepted, map[string]string{"jobId": strconv.Itoa(len(s.runs))})
+}
+
+func (s *Server) listLogs(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.recentLogs("", 100))
+}
+
+func (s *Server) taskLogs(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.Task(id); !ok {
+		writeError(w, http.StatusNotFound, "Task not found", "task_not_found")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.recentLogs(id, 50))
+}
+
+// recentLogs returns up to limit logs, newest first, for one task or, when
+// taskID is empty, for all of them
+func (s *Server) recentLogs(taskID string, limit int) []api.LogEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	logs := []api.LogEntry{}
+	for _, entry := range s.logs {
+		if taskID == "" || entry.TaskID == taskID {
+			logs = append(logs, entry)
+		}
+	}
+	sort.SliceStable(logs, func(i, j int) bool { return logs[i].ExecutedAt.After(logs[j].ExecutedAt) })
+	if len(logs) > limit {
+		logs = logs[:limit]
+	}
+	return logs
+}
+
+// ifMatch reports whether an If-Match header allows changing task
+func ifMatch(header string, task api.Task) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == task.ETag() {
+			return true
+		}
+	}
+	return false
+}
+
+// now is the server clock, at the millisecond precision the real server
+// stores
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message, code string) {
+	writeJSON(w, status, map[string]string{"error": message, "code": code})
+}
@@ -0,0 +1,139 @@
+// ABOUTME: Re-login prompt shown when the server rejects the client's token
+// ABOUTME: Tries a new bearer token and saves it to the profile's token file
+
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/v2/textinput"
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/config"
+	"github.com/jem-computer/ritual/tui/internal/styles"
+	"github.com/jem-computer/ritual/tui/internal/theme"
+)
+
+// login is the token prompt overlaying the active tab
+type login struct {
+	active   bool
+	checking bool // a token is being tried against the server
+	input    textinput.Model
+	err      string
+}
+
+func newLogin() login {
+	input := textinput.New()
+	input.Placeholder = "paste your API token"
+	input.EchoMode = textinput.EchoPassword
+	input.CharLimit = 512
+	return login{input: input}
+}
+
+func (l *login) open() tea.Cmd {
+	l.active = true
+	l.checking = false
+	l.err = ""
+	l.input.SetValue("")
+	return l.input.Focus()
+}
+
+func (l *login) close() {
+	l.active = false
+	l.input.Blur()
+}
+
+// loginResultMsg reports whether the server accepted a new token
+type loginResultMsg struct {
+	err error
+}
+
+// tryToken installs token on the shared client and checks it with a cheap
+// request; the client's connection state tells every component the outcome
+func tryToken(client *api.Client, profile config.Profile, token string) tea.Cmd {
+	return func() tea.Msg {
+		client.SetToken(token)
+		if _, err := client.GetTasksContext(context.Background()); err != nil {
+			return loginResultMsg{err: err}
+		}
+
+		if _, err := profile.SaveToken(token); err != nil {
+			return loginResultMsg{err: fmt.Errorf("token accepted but not saved: %w", err)}
+		}
+		return loginResultMsg{}
+	}
+}
+
+func (l login) update(msg tea.KeyMsg, client *api.Client, profile config.Profile) (login, tea.Cmd) {
+	if l.checking {
+		return l, nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		l.close()
+		return l, nil
+
+	case "enter":
+		token := strings.TrimSpace(l.input.Value())
+		if token == "" {
+			l.err = "enter a token, or esc to dismiss"
+			return l, nil
+		}
+		l.checking = true
+		l.err = ""
+		return l, tryToken(client, profile, token)
+	}
+
+	var cmd tea.Cmd
+	l.input, cmd = l.input.Update(msg)
+	return l, cmd
+}
+
+// result applies the outcome of tryToken
+func (l *login) result(msg loginResultMsg) {
+	l.checking = false
+	switch {
+	case msg.err == nil:
+		l.close()
+		return
+	case api.IsUnauthorized(msg.err):
+		l.err = "The server rejected that token"
+	default:
+		l.err = msg.err.Error()
+	}
+	l.input.SetValue("")
+}
+
+func (l login) view(profile config.Profile, width, height int) string {
+	t := theme.CurrentTheme()
+
+	title := styles.NewStyle().Foreground(t.Error()).Bold(true).Render("🔒 Unauthorized")
+	body := styles.NewStyle().Foreground(t.Text()).
+		Render(fmt.Sprintf("%s rejected the current credentials.\nEnter an API token to log in again.", profile.Server))
+
+	persistence := "The token will be saved to " + profile.TokenFile + "."
+	if profile.TokenFile == "" {
+		persistence = "The token lasts for this session; set token_file in the profile to keep it."
+	}
+	body += "\n" + styles.NewStyle().Foreground(t.TextMuted()).Render(persistence)
+
+	status := styles.NewStyle().Foreground(t.TextMuted()).Render("enter to log in • esc to dismiss (ctrl+l reopens)")
+	if l.checking {
+		status = styles.NewStyle().Foreground(t.Warning()).Render("Checking token…")
+	} else if l.err != "" {
+		status = styles.NewStyle().Foreground(t.Error()).Render(l.err)
+	}
+
+	box := styles.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Error()).
+		Padding(1, 2).
+		Width(min(64, width-4)).
+		Render(lipgloss.JoinVertical(lipgloss.Left, title, "", body, "", l.input.View(), "", status))
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
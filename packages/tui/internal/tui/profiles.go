@@ -0,0 +1,218 @@
+// ABOUTME: Server profile switching for the TUI
+// ABOUTME: Rebuilds every component against a new client and drops the old client's late results
+
+package tui
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+	"github.com/charmbracelet/lipgloss/v2"
+	"github.com/charmbracelet/lipgloss/v2/compat"
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/components/calendar"
+	"github.com/jem-computer/ritual/tui/internal/components/common"
+	"github.com/jem-computer/ritual/tui/internal/components/create"
+	"github.com/jem-computer/ritual/tui/internal/components/dashboard"
+	"github.com/jem-computer/ritual/tui/internal/components/logs"
+	"github.com/jem-computer/ritual/tui/internal/components/settings"
+	"github.com/jem-computer/ritual/tui/internal/config"
+	"github.com/jem-computer/ritual/tui/internal/styles"
+	"github.com/jem-computer/ritual/tui/internal/theme"
+)
+
+// Session is the server the TUI is talking to
+type Session struct {
+	Name    string // profile name; empty for the built-in local default
+	Profile config.Profile
+	Client  *api.Client
+}
+
+// Connector builds the client for a profile, applying process-wide
+// settings such as the retry policy
+type Connector func(config.Profile) (*api.Client, error)
+
+// generationMsg wraps a command's result with the profile generation that
+// issued it. After a switch, results from the previous server's requests
+// are dropped instead of reaching the rebuilt components.
+type generationMsg struct {
+	generation int
+	msg        tea.Msg
+}
+
+// tag wraps cmd so its result carries the current generation
+func (m Model) tag(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	generation := m.generation
+	return func() tea.Msg {
+		msg := cmd()
+		if msg == nil {
+			return nil
+		}
+		return generationMsg{generation: generation, msg: msg}
+	}
+}
+
+// untag handles a tagged result: stale ones are dropped, batches have
+// their commands tagged in turn, and Bubbletea's own messages go back to
+// the runtime, which acts on them only when they arrive untagged
+func (m Model) untag(msg generationMsg) (tea.Model, tea.Cmd) {
+	if msg.generation != m.generation {
+		return m, nil
+	}
+
+	switch inner := msg.msg.(type) {
+	case tea.BatchMsg:
+		cmds := make(tea.BatchMsg, len(inner))
+		for i, cmd := range inner {
+			cmds[i] = m.tag(cmd)
+		}
+		return m, func() tea.Msg { return cmds }
+	}
+
+	if reflect.TypeOf(msg.msg).PkgPath() == reflect.TypeOf(tea.BatchMsg{}).PkgPath() {
+		return m, func() tea.Msg { return msg.msg }
+	}
+	return m.Update(msg.msg)
+}
+
+// buildComponents creates every component against the session's client
+func (m *Model) buildComponents() {
+	client := m.session.Client
+	m.dashboard = dashboard.New(client)
+	m.create = create.New(client)
+	m.calendar = calendar.New(client)
+	m.logs = logs.New(client)
+	m.settings = settings.New(client)
+}
+
+// switchProfile connects to the named profile and rebuilds the components,
+// which reload from the new server
+func (m *Model) switchProfile(name string) (tea.Cmd, error) {
+	_, profile, err := m.config.Profile(name)
+	if err != nil {
+		return nil, err
+	}
+	client, err := m.connect(profile)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	m.session = Session{Name: name, Profile: profile, Client: client}
+	m.generation++
+	m.connState = client.ConnState()
+	m.login.close()
+	m.activeTab = DashboardTab
+	m.buildComponents()
+
+	cmds := []tea.Cmd{m.initComponents(), common.WaitForConnection(client)}
+	if m.width > 0 {
+		cmds = append(cmds, m.broadcast(tea.WindowSizeMsg{Width: m.width, Height: m.height})...)
+	}
+	return tea.Batch(cmds...), nil
+}
+
+// switcher is the profile picker overlaying the active tab
+type switcher struct {
+	active bool
+	names  []string
+	cursor int
+	err    string
+}
+
+func (s *switcher) open(cfg config.Config, current string) {
+	s.active = true
+	s.err = ""
+	s.names = cfg.Names()
+	s.cursor = 0
+	for i, name := range s.names {
+		if name == current {
+			s.cursor = i
+		}
+	}
+}
+
+// update handles a key in the picker and returns the chosen profile name,
+// if one was picked
+func (s switcher) update(msg tea.KeyMsg) (switcher, string) {
+	switch msg.String() {
+	case "esc":
+		s.active = false
+	case "up", "k":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case "down", "j":
+		if s.cursor < len(s.names)-1 {
+			s.cursor++
+		}
+	case "enter":
+		if len(s.names) > 0 {
+			return s, s.names[s.cursor]
+		}
+	}
+	return s, ""
+}
+
+func (s switcher) view(cfg config.Config, current string, width, height int) string {
+	t := theme.CurrentTheme()
+
+	title := styles.NewStyle().Foreground(t.Primary()).Bold(true).Render("Switch server profile")
+
+	var rows []string
+	if len(s.names) == 0 {
+		path, _ := config.Path()
+		rows = append(rows, styles.NewStyle().Foreground(t.TextMuted()).
+			Render("No profiles configured.\nAdd them under profiles: in "+path))
+	}
+	for i, name := range s.names {
+		profile := cfg.Profiles[name]
+
+		marker := "  "
+		if name == current {
+			marker = "● "
+		}
+		label := profileLabel(name, profile.Accent)
+		if i == s.cursor {
+			label = styles.NewStyle().Foreground(t.Primary()).Bold(true).Render("› ") + label
+		} else {
+			label = "  " + label
+		}
+		rows = append(rows, label+" "+styles.NewStyle().Foreground(t.TextMuted()).Render(marker+profile.Server))
+	}
+
+	help := styles.NewStyle().Foreground(t.TextMuted()).Render("↑/↓ choose • enter switch • esc cancel")
+	if s.err != "" {
+		help = styles.NewStyle().Foreground(t.Error()).Render(s.err)
+	}
+
+	box := styles.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Primary()).
+		Padding(1, 2).
+		Width(min(72, width-4)).
+		Render(lipgloss.JoinVertical(lipgloss.Left, title, "", strings.Join(rows, "\n"), "", help))
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// profileLabel renders a profile name as a chip in its accent color, or
+// the theme's accent when the profile doesn't set one
+func profileLabel(name, accent string) string {
+	t := theme.CurrentTheme()
+
+	style := styles.NewStyle().
+		Foreground(t.Background()).
+		Background(t.Accent()).
+		Bold(true).
+		Padding(0, 1)
+	if accent != "" {
+		c := lipgloss.Color(accent)
+		style = style.Background(compat.AdaptiveColor{Light: c, Dark: c})
+	}
+	return style.Render(name)
+}
@@ -11,10 +11,13 @@ import (
 	tea "github.com/charmbracelet/bubbletea/v2"
 	"github.com/charmbracelet/lipgloss/v2"
 	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/components/calendar"
+	"github.com/jem-computer/ritual/tui/internal/components/common"
 	"github.com/jem-computer/ritual/tui/internal/components/create"
 	"github.com/jem-computer/ritual/tui/internal/components/dashboard"
 	"github.com/jem-computer/ritual/tui/internal/components/logs"
 	"github.com/jem-computer/ritual/tui/internal/components/settings"
+	"github.com/jem-computer/ritual/tui/internal/config"
 	"github.com/jem-computer/ritual/tui/internal/styles"
 	"github.com/jem-computer/ritual/tui/internal/theme"
 )
@@ -24,19 +27,35 @@ type Tab int
 const (
 	DashboardTab Tab = iota
 	CreateTab
+	CalendarTab
 	LogsTab
 	SettingsTab
 )
 
+// tabCount is the number of tabs in the tab bar
+const tabCount = 5
+
 type Model struct {
 	width, height int
 	activeTab     Tab
-	client        *api.Client
 	version       string
+	connState     api.ConnState
+
+	// The server profile in use, and how to connect to the others
+	config     config.Config
+	session    Session
+	connect    Connector
+	generation int // bumped on every profile switch
+
+	// Overlays: the token prompt shown while the server rejects the client,
+	// and the profile picker
+	login    login
+	switcher switcher
 
 	// Components
 	dashboard dashboard.Model
 	create    create.Model
+	calendar  calendar.Model
 	logs      logs.Model
 	settings  settings.Model
 
@@ -49,6 +68,8 @@ type keyMap struct {
 	ShiftTab key.Binding
 	Quit     key.Binding
 	Help     key.Binding
+	Login    key.Binding
+	Profiles key.Binding
 }
 
 func defaultKeyMap() keyMap {
@@ -69,23 +90,40 @@ func defaultKeyMap() keyMap {
 			key.WithKeys("?"),
 			key.WithHelp("?", "help"),
 		),
+		Login: key.NewBinding(
+			key.WithKeys("ctrl+l"),
+			key.WithHelp("ctrl+l", "log in"),
+		),
+		Profiles: key.NewBinding(
+			key.WithKeys("ctrl+p"),
+			key.WithHelp("ctrl+p", "switch profile"),
+		),
 	}
 }
 
-func New(client *api.Client, version string) Model {
-	return Model{
+// New creates the TUI for session. cfg and connect are used to switch to
+// the other configured profiles.
+func New(cfg config.Config, session Session, connect Connector, version string) Model {
+	m := Model{
 		activeTab: DashboardTab,
-		client:    client,
 		version:   version,
-		dashboard: dashboard.New(client),
-		create:    create.New(client),
-		logs:      logs.New(client),
-		settings:  settings.New(client),
+		config:    cfg,
+		session:   session,
+		connect:   connect,
+		login:     newLogin(),
 		keys:      defaultKeyMap(),
 	}
+	m.buildComponents()
+	return m
 }
 
 func (m Model) Init() (tea.Model, tea.Cmd) {
+	cmd := m.initComponents()
+	return m, m.tag(tea.Batch(cmd, common.WaitForConnection(m.session.Client)))
+}
+
+// initComponents runs every component's Init
+func (m *Model) initComponents() tea.Cmd {
 	var cmds []tea.Cmd
 
 	dashboardModel, cmd := m.dashboard.Init()
@@ -96,6 +134,10 @@ func (m Model) Init() (tea.Model, tea.Cmd) {
 	m.create = createModel.(create.Model)
 	cmds = append(cmds, cmd)
 
+	calendarModel, cmd := m.calendar.Init()
+	m.calendar = calendarModel.(calendar.Model)
+	cmds = append(cmds, cmd)
+
 	logsModel, cmd := m.logs.Init()
 	m.logs = logsModel.(logs.Model)
 	cmds = append(cmds, cmd)
@@ -104,10 +146,20 @@ func (m Model) Init() (tea.Model, tea.Cmd) {
 	m.settings = settingsModel.(settings.Model)
 	cmds = append(cmds, cmd)
 
-	return m, tea.Batch(cmds...)
+	return tea.Batch(cmds...)
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(generationMsg); ok {
+		return m.untag(msg)
+	}
+
+	updated, cmd := m.update(msg)
+	model := updated.(Model)
+	return model, model.tag(cmd)
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
@@ -115,33 +167,74 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 
-		// Update all components with new size
-		dashboardModel, cmd := m.dashboard.Update(msg)
-		m.dashboard = dashboardModel.(dashboard.Model)
-		cmds = append(cmds, cmd)
+	case common.ConnectionMsg:
+		// Components see it too, through the broadcast below
+		m.connState = msg.State
+		cmds = append(cmds, common.WaitForConnection(m.session.Client))
+		if msg.State == api.Unauthorized && !m.login.active {
+			cmds = append(cmds, m.login.open())
+		}
 
-		createModel, cmd := m.create.Update(msg)
-		m.create = createModel.(create.Model)
-		cmds = append(cmds, cmd)
+	case loginResultMsg:
+		m.login.result(msg)
+		return m, nil
 
-		logsModel, cmd := m.logs.Update(msg)
-		m.logs = logsModel.(logs.Model)
-		cmds = append(cmds, cmd)
+	case calendar.OpenTaskMsg:
+		m.activeTab = DashboardTab
+		m.dashboard.SelectTask(msg.TaskID)
+		return m, nil
 
-		settingsModel, cmd := m.settings.Update(msg)
-		m.settings = settingsModel.(settings.Model)
-		cmds = append(cmds, cmd)
+	case dashboard.CloneTaskMsg:
+		m.activeTab = CreateTab
+		m.create.Clone(msg.Task)
+		return m, nil
 
 	case tea.KeyMsg:
-		switch {
-		case key.Matches(msg, m.keys.Quit):
+		if key.Matches(msg, m.keys.Quit) {
 			return m, tea.Quit
+		}
+		if m.login.active {
+			var cmd tea.Cmd
+			m.login, cmd = m.login.update(msg, m.session.Client, m.session.Profile)
+			return m, cmd
+		}
+		if m.switcher.active {
+			var name string
+			m.switcher, name = m.switcher.update(msg)
+			if name == "" {
+				return m, nil
+			}
+			if name == m.session.Name {
+				m.switcher.active = false
+				return m, nil
+			}
+			cmd, err := m.switchProfile(name)
+			if err != nil {
+				m.switcher.err = err.Error()
+				return m, nil
+			}
+			m.switcher.active = false
+			return m, cmd
+		}
+
+		previous := m.activeTab
+		switch {
+		case key.Matches(msg, m.keys.Profiles):
+			m.switcher.open(m.config, m.session.Name)
+			return m, nil
+
+		case key.Matches(msg, m.keys.Login) && m.connState == api.Unauthorized:
+			return m, m.login.open()
 
 		case key.Matches(msg, m.keys.Tab):
-			m.activeTab = (m.activeTab + 1) % 4
+			m.activeTab = (m.activeTab + 1) % tabCount
 
 		case key.Matches(msg, m.keys.ShiftTab):
-			m.activeTab = (m.activeTab + 3) % 4
+			m.activeTab = (m.activeTab + tabCount - 1) % tabCount
+
+		// Letter shortcuts would swallow typing in forms and prompts
+		case m.activeTab == CreateTab && m.create.Editing(),
+			m.activeTab == DashboardTab && m.dashboard.Editing():
 
 		case msg.String() == "d":
 			m.activeTab = DashboardTab
@@ -155,6 +248,27 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case msg.String() == "s":
 			m.activeTab = SettingsTab
 		}
+
+		// The key that switched tabs isn't meant for the tab it switched to,
+		// e.g. "c" would be typed into the create form's name
+		if m.activeTab != previous {
+			return m, nil
+		}
+	}
+
+	// Input (key presses, pastes and the mouse) is routed to the active
+	// component only, so a paste on one tab isn't typed into another's form;
+	// everything else (resizes, ticks, async results) reaches all of them so
+	// background tabs stay current.
+	switch msg.(type) {
+	case tea.KeyMsg:
+	case tea.PasteMsg, tea.PasteStartMsg, tea.PasteEndMsg, tea.MouseMsg:
+		if m.login.active || m.switcher.active {
+			return m, tea.Batch(cmds...)
+		}
+	default:
+		cmds = append(cmds, m.broadcast(msg)...)
+		return m, tea.Batch(cmds...)
 	}
 
 	// Update active component
@@ -169,6 +283,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.create = newModel.(create.Model)
 		cmds = append(cmds, cmd)
 
+	case CalendarTab:
+		newModel, cmd := m.calendar.Update(msg)
+		m.calendar = newModel.(calendar.Model)
+		cmds = append(cmds, cmd)
+
 	case LogsTab:
 		newModel, cmd := m.logs.Update(msg)
 		m.logs = newModel.(logs.Model)
@@ -183,6 +302,33 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// broadcast delivers msg to every component
+func (m *Model) broadcast(msg tea.Msg) []tea.Cmd {
+	var cmds []tea.Cmd
+
+	dashboardModel, cmd := m.dashboard.Update(msg)
+	m.dashboard = dashboardModel.(dashboard.Model)
+	cmds = append(cmds, cmd)
+
+	createModel, cmd := m.create.Update(msg)
+	m.create = createModel.(create.Model)
+	cmds = append(cmds, cmd)
+
+	calendarModel, cmd := m.calendar.Update(msg)
+	m.calendar = calendarModel.(calendar.Model)
+	cmds = append(cmds, cmd)
+
+	logsModel, cmd := m.logs.Update(msg)
+	m.logs = logsModel.(logs.Model)
+	cmds = append(cmds, cmd)
+
+	settingsModel, cmd := m.settings.Update(msg)
+	m.settings = settingsModel.(settings.Model)
+	cmds = append(cmds, cmd)
+
+	return cmds
+}
+
 func (m Model) View() string {
 	if m.width == 0 || m.height == 0 {
 		return ""
@@ -200,11 +346,19 @@ func (m Model) View() string {
 		content = m.dashboard.View()
 	case CreateTab:
 		content = m.create.View()
+	case CalendarTab:
+		content = m.calendar.View()
 	case LogsTab:
 		content = m.logs.View()
 	case SettingsTab:
 		content = m.settings.View()
 	}
+	switch {
+	case m.login.active:
+		content = m.login.view(m.session.Profile, m.width, contentHeight)
+	case m.switcher.active:
+		content = m.switcher.view(m.config, m.session.Name, m.width, contentHeight)
+	}
 
 	// Ensure content fills the available space
 	contentLines := strings.Split(content, "\n")
@@ -230,7 +384,7 @@ func (m Model) renderTabBar() string {
 		return "No theme"
 	}
 
-	tabs := []string{"Dashboard", "Create", "Logs", "Settings"}
+	tabs := []string{"Dashboard", "Create", "Calendar", "Logs", "Settings"}
 	var renderedTabs []string
 
 	for i, tab := range tabs {
@@ -263,6 +417,16 @@ func (m Model) renderTabBar() string {
 
 	tabsSection := lipgloss.JoinHorizontal(lipgloss.Top, renderedTabs...)
 
+	// The profile sits right by the logo so it's clear which server a
+	// change will go to
+	if m.session.Name != "" {
+		logo = lipgloss.JoinHorizontal(lipgloss.Top, logo, profileLabel(m.session.Name, m.session.Profile.Accent),
+			styles.NewStyle().Background(t.BackgroundPanel()).Render(" "))
+	}
+	if indicator := common.ConnectionIndicator(m.connState); indicator != "" {
+		logo = lipgloss.JoinHorizontal(lipgloss.Top, logo, indicator)
+	}
+
 	// Calculate spacing between logo and tabs
 	logoWidth := lipgloss.Width(logo)
 	tabsWidth := lipgloss.Width(tabsSection)
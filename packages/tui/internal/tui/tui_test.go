@@ -0,0 +1,229 @@
+// ABOUTME: Golden-frame tests for the TUI against an in-memory server
+// ABOUTME: Drives the model with key presses and compares what it renders at fixed sizes
+
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea/v2"
+
+	"github.com/jem-computer/ritual/tui/internal/api"
+	"github.com/jem-computer/ritual/tui/internal/components/common"
+	"github.com/jem-computer/ritual/tui/internal/config"
+	"github.com/jem-computer/ritual/tui/internal/testutil"
+)
+
+// now is when the tests take place, a Monday morning
+var now = time.Date(2025, time.June, 2, 8, 0, 0, 0, time.UTC)
+
+func TestMain(m *testing.M) {
+	common.Now = func() time.Time { return now }
+	time.Local = time.UTC
+	os.Exit(m.Run())
+}
+
+// sizes are the window sizes every frame is checked at
+var sizes = []struct{ width, height int }{
+	{80, 24},
+	{120, 40},
+}
+
+// newTestServer returns a server with a few tasks in different states
+func newTestServer(t *testing.T) *testutil.Server {
+	t.Helper()
+
+	created := now.Add(-30 * 24 * time.Hour)
+	srv := testutil.NewServer(t)
+
+	digest := srv.AddTask(api.Task{
+		Name:      "Morning digest",
+		Prompt:    "Summarise the news for {{var \"topic\"}}",
+		Schedule:  "0 9 * * *",
+		Model:     "claude-sonnet",
+		Output:    "email",
+		Status:    "ACTIVE",
+		LastRun:   now.Add(-23 * time.Hour),
+		CreatedAt: created.Add(2 * time.Hour),
+		Variables: map[string]string{"topic": "astronomy"},
+	})
+	srv.AddTask(api.Task{
+		Name:      "Weekly review",
+		Prompt:    "Review the week",
+		Schedule:  "0 17 * * 5",
+		Model:     "claude-sonnet",
+		Output:    "slack",
+		Status:    "PAUSED",
+		CreatedAt: created.Add(time.Hour),
+	})
+	srv.AddTask(api.Task{
+		Name:      "Quarterly report",
+		Prompt:    "Draft the quarterly report",
+		Schedule:  "0 9 1 */3 *",
+		Model:     "claude-opus",
+		Output:    "file",
+		Status:    "ACTIVE",
+		CreatedAt: created,
+		ManagedBy: "rituals.yaml",
+	})
+
+	for i, status := range []string{"SUCCESS", "SUCCESS", "FAILURE"} {
+		srv.AddLog(api.LogEntry{
+			TaskID:     digest.ID,
+			Prompt:     digest.Prompt,
+			Output:     fmt.Sprintf("Digest %d", i+1),
+			Status:     status,
+			ExecutedAt: digest.LastRun.Add(-time.Duration(i) * 24 * time.Hour),
+			Duration:   1200,
+		})
+	}
+	return srv
+}
+
+// newDriver starts the TUI against srv with nothing read from or written
+// to the user's own config and cache
+func newDriver(t *testing.T, srv *testutil.Server, width, height int) *testutil.Driver {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir+"/config")
+	t.Setenv("XDG_CACHE_HOME", dir+"/cache")
+	t.Setenv("XDG_RUNTIME_DIR", dir+"/run")
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "vi")
+
+	session := Session{Client: srv.Client()}
+	return testutil.NewDriver(t, New(config.Config{}, session, nil, "test"), width, height)
+}
+
+func TestDashboard(t *testing.T) {
+	for _, size := range sizes {
+		t.Run(fmt.Sprintf("%dx%d", size.width, size.height), func(t *testing.T) {
+			d := newDriver(t, newTestServer(t), size.width, size.height)
+			d.Golden(fmt.Sprintf("dashboard_%dx%d", size.width, size.height))
+		})
+	}
+}
+
+// The server doesn't keep nextRun, so countdowns come from the schedule
+func TestDashboardNextRunFromSchedule(t *testing.T) {
+	srv := testutil.NewServer(t)
+	srv.AddTask(api.Task{Name: "Standup", Schedule: "58 7 * * *", Status: "ACTIVE", LastRun: now.Add(-24 * time.Hour)})
+	srv.AddTask(api.Task{Name: "Lunch", Schedule: "daily at 12:30 pm", Status: "ACTIVE", LastRun: now.Add(-time.Hour)})
+	d := newDriver(t, srv, 100, 24)
+
+	view := d.View()
+	for _, want := range []string{"● RUNNING • Started 2m ago", "Next: in 4h 30m"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("dashboard doesn't show %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestDashboardPause(t *testing.T) {
+	srv := newTestServer(t)
+	d := newDriver(t, srv, 80, 24)
+
+	d.Press("p")
+	d.Golden("dashboard_paused")
+
+	for _, task := range srv.Tasks() {
+		if task.Name == "Morning digest" && task.Status != "PAUSED" {
+			t.Errorf("server has %s %s, want PAUSED", task.Name, task.Status)
+		}
+	}
+}
+
+func TestDashboardRunHistory(t *testing.T) {
+	srv := newTestServer(t)
+	d := newDriver(t, srv, 80, 24)
+
+	d.Press("L")
+	d.Golden("runs_output")
+
+	d.Press("down", "v")
+	d.Golden("runs_diff")
+
+	d.Press("r")
+	if runs := srv.Runs(); len(runs) != 1 {
+		t.Errorf("server queued %d runs, want 1", len(runs))
+	}
+
+	d.Press("esc")
+	if view := d.View(); strings.Contains(view, "◂") || !strings.Contains(view, "SCHEDULED TASKS") {
+		t.Errorf("esc didn't go back to the task list:\n%s", view)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	for _, size := range sizes {
+		t.Run(fmt.Sprintf("%dx%d", size.width, size.height), func(t *testing.T) {
+			d := newDriver(t, newTestServer(t), size.width, size.height)
+			d.Press("c")
+			d.Golden(fmt.Sprintf("create_%dx%d", size.width, size.height))
+		})
+	}
+}
+
+// The key that switches tabs used to be delivered to the tab it opened too,
+// so "c" started every new task's name with a c
+func TestTabKeyIsNotTypedIntoTheTab(t *testing.T) {
+	d := newDriver(t, newTestServer(t), 100, 40)
+	d.Press("c")
+	if view := d.View(); !strings.Contains(view, "Daily Standup Summary") {
+		t.Errorf("the name field isn't empty after pressing c:\n%s", view)
+	}
+}
+
+// Pastes used to reach every tab, so one on the dashboard was typed into
+// the create form's focused field
+func TestPasteGoesToTheActiveTabOnly(t *testing.T) {
+	d := newDriver(t, newTestServer(t), 100, 40)
+	d.Send(tea.PasteMsg("Evening summary"))
+	d.Press("c")
+	if view := d.View(); !strings.Contains(view, "Daily Standup Summary") {
+		t.Errorf("the paste on the dashboard reached the create form:\n%s", view)
+	}
+
+	d.Send(tea.PasteMsg("Evening summary"))
+	if view := d.View(); !strings.Contains(view, "Evening summary") {
+		t.Errorf("the paste on the create tab didn't reach its name field:\n%s", view)
+	}
+}
+
+// Tab shortcuts work on the create form's pickers, which take no typing
+func TestTabKeyOnCreatePicker(t *testing.T) {
+	d := newDriver(t, newTestServer(t), 100, 40)
+	d.Press("c", "l")
+	if tab := d.Model().(Model).activeTab; tab != CreateTab {
+		t.Fatalf("l in the name field switched to tab %d", tab)
+	}
+
+	d.Press("down", "down", "l")
+	if tab := d.Model().(Model).activeTab; tab != LogsTab {
+		t.Errorf("l on the schedule picker left tab %d open, want the logs", tab)
+	}
+}
+
+func TestCreateTyping(t *testing.T) {
+	d := newDriver(t, newTestServer(t), 100, 40)
+	d.Press("c")
+	d.Type("Evening summary")
+	d.Press("down")
+	d.Type("Summarise today for {{var \"team\"}}")
+	d.Golden("create_typed")
+}
+
+func TestSettings(t *testing.T) {
+	for _, size := range sizes {
+		t.Run(fmt.Sprintf("%dx%d", size.width, size.height), func(t *testing.T) {
+			d := newDriver(t, newTestServer(t), size.width, size.height)
+			d.Press("s", "down", "down")
+			d.Golden(fmt.Sprintf("settings_%dx%d", size.width, size.height))
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// ABOUTME: Hand-written runtime for the generated Ritual API client
+// ABOUTME: Sends JSON requests through a pluggable HTTPDoer and turns failed responses into *APIError
+
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// HTTPDoer sends requests; *http.Client satisfies it. Wrap one to add
+// credentials or route requests elsewhere.
+type HTTPDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client calls the Ritual API. Its methods, one per operation in the spec,
+// make a single attempt each; retrying is left to the caller.
+type Client struct {
+	server string
+	doer   HTTPDoer
+}
+
+// NewClient creates a client for the server at server, an http(s) URL
+// without a trailing slash. A nil doer uses http.DefaultClient.
+func NewClient(server string, doer HTTPDoer) *Client {
+	if doer == nil {
+		doer = http.DefaultClient
+	}
+	return &Client{server: server, doer: doer}
+}
+
+// do sends a request with body encoded as JSON when non-nil, and decodes
+// the response into out when non-nil. Any status other than want is
+// returned as an *APIError.
+func (c *Client) do(ctx context.Context, method, path string, header http.Header, body any, want int, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.server+path, reader)
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != want {
+		return newAPIError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,57 @@
+// ABOUTME: Structured errors for failed API responses
+// ABOUTME: Carries the server's status, error code, message and request ID
+
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBody caps how much of a non-JSON error body is kept as the message
+const maxErrorBody = 512
+
+// APIError is returned when the server answers with an unexpected status
+type APIError struct {
+	Status    int    // HTTP status code
+	Code      string // machine-readable code such as "task_not_found", if sent
+	Message   string // human-readable message from the server
+	RequestID string // the server's X-Request-Id, for matching server logs
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = strings.ToLower(http.StatusText(e.Status))
+	}
+	if e.RequestID != "" {
+		return fmt.Sprintf("%s (HTTP %d, request %s)", msg, e.Status, e.RequestID)
+	}
+	return fmt.Sprintf("%s (HTTP %d)", msg, e.Status)
+}
+
+// newAPIError builds an *APIError from a failed response, reading the
+// server's Error body when there is one
+func newAPIError(resp *http.Response) *APIError {
+	e := &APIError{
+		Status:    resp.StatusCode,
+		RequestID: resp.Header.Get("X-Request-Id"),
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if err != nil {
+		return e
+	}
+
+	var body Error
+	if json.Unmarshal(data, &body) == nil {
+		e.Message = body.Error
+		e.Code = body.Code
+	} else {
+		e.Message = strings.TrimSpace(string(data))
+	}
+	return e
+}
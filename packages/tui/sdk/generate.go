@@ -0,0 +1,9 @@
+// ABOUTME: Go client for the Ritual server's HTTP API
+// ABOUTME: Types and methods in sdk.gen.go are generated from packages/server/openapi.yaml
+
+// Package sdk is a client for the Ritual API generated from its OpenAPI
+// spec. The TUI and CLI use it through internal/api, which adds retries,
+// the circuit breaker, credentials and Unix sockets.
+package sdk
+
+//go:generate go run ../cmd/sdkgen -spec ../../server/openapi.yaml -out sdk.gen.go
@@ -0,0 +1,31 @@
+// ABOUTME: Checks the generated SDK is in step with the server's OpenAPI spec
+// ABOUTME: Fails when openapi.yaml or the generator changed without regenerating sdk.gen.go
+
+package sdk
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/jem-computer/ritual/tui/internal/codegen"
+)
+
+func TestGeneratedCodeMatchesSpec(t *testing.T) {
+	spec, err := os.ReadFile("../../server/openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := codegen.Generate(spec, codegen.Options{Package: "sdk", Source: "openapi.yaml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile("sdk.gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("sdk.gen.go is out of date with openapi.yaml; run go generate ./sdk in packages/tui")
+	}
+}
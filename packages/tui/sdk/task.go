@@ -0,0 +1,12 @@
+// ABOUTME: Hand-written helpers on the generated task type
+// ABOUTME: Derives the ETag the server sends for a version of a task
+
+package sdk
+
+import "strconv"
+
+// ETag returns the entity tag the server gives this version of the task,
+// derived from UpdatedAt
+func (t Task) ETag() string {
+	return `"` + strconv.FormatInt(t.UpdatedAt.UnixMilli(), 10) + `"`
+}
@@ -60,10 +60,18 @@ if (!isRedisRunning()) {
   console.log('Redis is already running');
 }
 
+// Serve on a Unix socket in the user's private runtime dir when there is
+// one, so no TCP port is opened and other local users can't reach the
+// server. Without XDG_RUNTIME_DIR (e.g. on macOS) fall back to the port.
+const runtimeDir = process.env.XDG_RUNTIME_DIR;
+const socketPath = runtimeDir ? join(runtimeDir, 'ritual', 'ritual.sock') : null;
+const serverURL = socketPath ? `unix://${socketPath}` : 'http://localhost:8080';
+
 // Start server in background
-console.log('Starting server...');
+console.log(`Starting server on ${socketPath ?? 'port 8080'}...`);
 const serverProcess = spawn('bun', ['run', 'dev'], {
   cwd: join(rootDir, 'packages/server'),
+  env: socketPath ? { ...process.env, RITUAL_SOCKET: socketPath } : process.env,
   stdio: ['ignore', 'pipe', 'pipe'],
   detached: false
 });
@@ -88,7 +96,7 @@ serverProcess.stderr.on('data', (data) => {
 // Wait a bit for server to start, then launch TUI
 setTimeout(() => {
   console.log('Starting TUI...');
-  const tuiProcess = spawn('go', ['run', 'cmd/ritual/main.go', '--server', 'http://localhost:8080'], {
+  const tuiProcess = spawn('go', ['run', 'cmd/ritual/main.go', '--server', serverURL], {
     cwd: join(rootDir, 'packages/tui'),
     stdio: 'inherit' // This gives TUI full control of the terminal
   });